@@ -0,0 +1,384 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+type command struct {
+	name  string
+	args  []string
+	flags *flag.FlagSet
+	def   *commandDef
+}
+
+// commandDef describes a command: what it does, how it's called and which
+// flags it takes. It's used both to run the command and to explain it.
+type commandDef struct {
+	name string
+	// usage lists the positional arguments, e.g. "<name> <url>". Arguments
+	// in <> are required, ones in [] are optional and "..." allows any number
+	// more; the number of arguments a command gets is checked against it.
+	usage       string
+	description string
+	flags       func(fs *flag.FlagSet)
+	handler     func(*state, command) error
+	subcommands []*commandDef
+	// complete returns the values the next positional argument could take,
+	// given the ones before it, for shell completion.
+	complete func(s *state, args []string) ([]string, error)
+	// hidden commands are left out of help and completion
+	hidden bool
+	// rawArgs passes the arguments to handler as they are, flags and all
+	rawArgs bool
+
+	parent *commandDef
+}
+
+type commands struct {
+	supportedCommands []*commandDef
+	globalFlags       *flag.FlagSet
+}
+
+// usageError is returned when a command is called the wrong way, so the
+// usage of def (or of gator itself, if def is nil) can be shown with it.
+type usageError struct {
+	def *commandDef
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (c *commands) run(s *state, cmd command) error {
+	cmd, err := c.expand(s, cmd)
+	if err != nil {
+		return err
+	}
+
+	return c.runExpanded(s, cmd)
+}
+
+// runExpanded runs cmd, which expand has already been through.
+func (c *commands) runExpanded(s *state, cmd command) error {
+	def := findCommand(c.supportedCommands, cmd.name)
+	if def == nil {
+		return usageError{err: fmt.Errorf("invalid command: %s", cmd.name)}
+	}
+
+	return c.runDef(s, def, cmd.args)
+}
+
+// expand replaces an alias from the config with the command it stands for,
+// keeping any arguments given after it.
+func (c *commands) expand(s *state, cmd command) (command, error) {
+	return c.expandAliases(s.cfg.Aliases(), cmd)
+}
+
+// expandAliases keeps expanding cmd for as long as it names one of aliases,
+// since an alias can stand for another one, but not for itself.
+func (c *commands) expandAliases(aliases map[string]string, cmd command) (command, error) {
+	var seen []string
+	for findCommand(c.supportedCommands, cmd.name) == nil {
+		expansion, ok := aliases[cmd.name]
+		if !ok {
+			break
+		}
+		if slices.Contains(seen, cmd.name) {
+			return command{}, fmt.Errorf("alias %s stands for itself: %s", cmd.name, strings.Join(append(seen, cmd.name), " -> "))
+		}
+		seen = append(seen, cmd.name)
+
+		words, err := splitArgs(expansion)
+		if err != nil {
+			return command{}, fmt.Errorf("alias %s: %w", cmd.name, err)
+		}
+		if len(words) == 0 {
+			return command{}, fmt.Errorf("alias %s doesn't stand for anything", cmd.name)
+		}
+
+		cmd = command{name: words[0], args: append(words[1:], cmd.args...)}
+	}
+
+	return cmd, nil
+}
+
+func (c *commands) runDef(s *state, def *commandDef, args []string) error {
+	if len(def.subcommands) != 0 {
+		if len(args) == 0 {
+			return def.usageErrorf("%s requires a subcommand: %s", def.path(), def.subcommandNames())
+		}
+		if isHelpFlag(args[0]) {
+			return c.printHelp(os.Stdout, def)
+		}
+
+		sub := findCommand(def.subcommands, args[0])
+		if sub == nil {
+			return def.usageErrorf("unknown %s subcommand: %s", def.path(), args[0])
+		}
+
+		return c.runDef(s, sub, args[1:])
+	}
+
+	if def.rawArgs {
+		return def.handler(s, command{name: def.path(), args: args, def: def})
+	}
+
+	fs := def.flagSet()
+	positional, err := parseFlags(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return c.printHelp(os.Stdout, def)
+	}
+	if err != nil {
+		return def.usageErrorf("%s", err)
+	}
+
+	minArgs, maxArgs := argCounts(def.usage)
+	if len(positional) < minArgs {
+		return def.usageErrorf("not enough arguments for %s", def.path())
+	}
+	if maxArgs >= 0 && len(positional) > maxArgs {
+		return def.usageErrorf("too many arguments for %s", def.path())
+	}
+
+	return def.handler(s, command{
+		name:  def.path(),
+		args:  positional,
+		flags: fs,
+		def:   def,
+	})
+}
+
+func (c *commands) register(def *commandDef) {
+	def.adopt()
+	c.supportedCommands = append(c.supportedCommands, def)
+}
+
+// adopt points each of def's subcommands (and theirs) back at their parent.
+func (def *commandDef) adopt() {
+	for _, sub := range def.subcommands {
+		sub.parent = def
+		sub.adopt()
+	}
+}
+
+// path returns the full name of the command, like "filter add".
+func (def *commandDef) path() string {
+	if def.parent == nil {
+		return def.name
+	}
+
+	return def.parent.path() + " " + def.name
+}
+
+func (def *commandDef) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(def.path(), flag.ContinueOnError)
+	if def.flags != nil {
+		def.flags(fs)
+	}
+
+	return fs
+}
+
+func (def *commandDef) subcommandNames() string {
+	names := make([]string, len(def.subcommands))
+	for i, sub := range def.subcommands {
+		names[i] = sub.name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func (def *commandDef) usageErrorf(format string, a ...any) error {
+	return usageError{def: def, err: fmt.Errorf(format, a...)}
+}
+
+func (cmd command) usageErrorf(format string, a ...any) error {
+	return cmd.def.usageErrorf(format, a...)
+}
+
+// stringFlag and boolFlag return the values of flags the command defines.
+// Asking for any other flag is a mistake in gator itself, so it panics.
+func (cmd command) stringFlag(name string) string {
+	return cmd.flag(name).Value.String()
+}
+
+func (cmd command) boolFlag(name string) bool {
+	b, ok := cmd.flag(name).Value.(flag.Getter).Get().(bool)
+	if !ok {
+		panic(fmt.Sprintf("--%s of %s isn't a bool flag", name, cmd.name))
+	}
+
+	return b
+}
+
+func (cmd command) flag(name string) *flag.Flag {
+	var f *flag.Flag
+	if cmd.flags != nil {
+		f = cmd.flags.Lookup(name)
+	}
+	if f == nil {
+		panic(fmt.Sprintf("%s has no --%s flag", cmd.name, name))
+	}
+
+	return f
+}
+
+func findCommand(defs []*commandDef, name string) *commandDef {
+	for _, def := range defs {
+		if def.name == name {
+			return def
+		}
+	}
+
+	return nil
+}
+
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// argCounts works out from a usage string how few and how many positional
+// arguments a command takes. max is -1 when there's no limit.
+func argCounts(usage string) (min, max int) {
+	for _, word := range strings.Fields(usage) {
+		if strings.Contains(word, "...") {
+			max = -1
+		}
+		if strings.HasPrefix(word, "<") {
+			min++
+		}
+		if max >= 0 {
+			max++
+		}
+	}
+
+	return min, max
+}
+
+func handlerHelp(c *commands) func(*state, command) error {
+	return func(s *state, cmd command) error {
+		if len(cmd.args) == 0 {
+			return c.printHelp(os.Stdout, nil)
+		}
+
+		def := findCommand(c.supportedCommands, cmd.args[0])
+		for _, name := range cmd.args[1:] {
+			if def == nil {
+				break
+			}
+			def = findCommand(def.subcommands, name)
+		}
+		if def == nil || def.hidden {
+			return cmd.usageErrorf("there's no command called '%s'", strings.Join(cmd.args, " "))
+		}
+
+		return c.printHelp(os.Stdout, def)
+	}
+}
+
+// printHelp explains def, or gator as a whole if def is nil.
+func (c *commands) printHelp(w io.Writer, def *commandDef) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if def == nil {
+		fmt.Fprintln(tw, "Usage: gator [global flags] <command> [arguments]")
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Commands:")
+		for _, def := range c.supportedCommands {
+			if !def.hidden {
+				fmt.Fprintf(tw, "  %s\t%s\n", def.name, def.description)
+			}
+		}
+		if c.globalFlags != nil {
+			fmt.Fprintln(tw)
+			fmt.Fprintln(tw, "Global flags:")
+			printFlags(tw, c.globalFlags)
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Run 'gator help <command>' for more about a command.")
+
+		return tw.Flush()
+	}
+
+	fmt.Fprintf(tw, "Usage: gator %s\n", def.synopsis())
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, def.description)
+
+	if len(def.subcommands) != 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Subcommands:")
+		for _, sub := range def.subcommands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.name, sub.description)
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintf(tw, "Run 'gator help %s <subcommand>' for more about a subcommand.\n", def.path())
+
+		return tw.Flush()
+	}
+
+	fs := def.flagSet()
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Flags:")
+		printFlags(tw, fs)
+	}
+
+	return tw.Flush()
+}
+
+// synopsis returns how def is called, like "filter add [flags] <action> <pattern>".
+func (def *commandDef) synopsis() string {
+	parts := []string{def.path()}
+
+	if len(def.subcommands) != 0 {
+		parts = append(parts, "<subcommand>")
+		return strings.Join(parts, " ")
+	}
+
+	if def.flags != nil {
+		parts = append(parts, "[flags]")
+	}
+	if def.usage != "" {
+		parts = append(parts, def.usage)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func printFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+
+		fmt.Fprintf(w, "  --%s", f.Name)
+		if name != "" {
+			fmt.Fprintf(w, " %s", name)
+		}
+		fmt.Fprintf(w, "\t%s", usage)
+		if f.DefValue != "" && f.DefValue != "false" {
+			fmt.Fprintf(w, " (default %s)", f.DefValue)
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+// reportError prints err for the user, along with the usage of the command
+// it came from when it's down to how that command was called.
+func (c *commands) reportError(w io.Writer, err error) {
+	fmt.Fprintf(w, "error: %s\n", err)
+
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintln(w)
+		c.printHelp(w, usageErr.def)
+	}
+}
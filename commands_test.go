@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args       string
+		positional []string
+		force      bool
+		name       string
+		err        bool
+	}{
+		{args: "", positional: []string{}},
+		{args: "a b", positional: []string{"a", "b"}},
+		{args: "--force a --name x b", positional: []string{"a", "b"}, force: true, name: "x"},
+		{args: "a b --force --name=x", positional: []string{"a", "b"}, force: true, name: "x"},
+		{args: "-force a -name x", positional: []string{"a"}, force: true, name: "x"},
+		// a lone dash is an argument, like stdin
+		{args: "- a", positional: []string{"-", "a"}},
+		{args: "a -- --force -b", positional: []string{"a", "--force", "-b"}},
+		{args: "--name x -- -- a", positional: []string{"--", "a"}, name: "x"},
+		{args: "a --unknown", err: true},
+		{args: "a --name", err: true},
+		{args: "--force=maybe", err: true},
+		{args: "a --help", err: true},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		force := fs.Bool("force", false, "")
+		name := fs.String("name", "", "")
+
+		positional, err := parseFlags(fs, strings.Fields(tc.args))
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: parsed without an error", tc.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.args, err)
+			continue
+		}
+		if !slices.Equal(positional, tc.positional) || *force != tc.force || *name != tc.name {
+			t.Errorf("%q: got %q, --force %v, --name %q", tc.args, positional, *force, *name)
+		}
+	}
+}
+
+func TestArgCounts(t *testing.T) {
+	c := &commands{globalFlags: flag.NewFlagSet("gator", flag.ContinueOnError)}
+	registerCommands(c)
+
+	want := map[string][2]int{
+		"help":           {0, 2},
+		"completion":     {1, 1},
+		"__complete":     {0, -1},
+		"login":          {1, 1},
+		"register":       {1, 1},
+		"purge":          {0, 0},
+		"migrate up":     {0, 0},
+		"migrate down":   {0, 0},
+		"migrate status": {0, 0},
+		"migrate redo":   {0, 0},
+		"profile list":   {0, 0},
+		"profile use":    {1, 1},
+		"profile add":    {2, 2},
+		"profile rm":     {1, 1},
+		"config get":     {1, 1},
+		"config set":     {2, 2},
+		"config unset":   {1, 1},
+		"config list":    {0, 0},
+		"config path":    {0, 0},
+		"alias add":      {2, -1},
+		"alias rm":       {1, 1},
+		"alias list":     {0, 0},
+		"users":          {0, 0},
+		"deleteuser":     {1, 1},
+		"renameuser":     {2, 2},
+		"whoami":         {0, 0},
+		"passwd":         {0, 0},
+		"agg":            {1, 1},
+		"addfeed":        {2, 2},
+		"feeds":          {0, 0},
+		"removefeed":     {1, 1},
+		"editfeed":       {1, 1},
+		"renamefeed":     {2, 2},
+		"follow":         {1, 1},
+		"following":      {0, 0},
+		"unfollow":       {1, 1},
+		"browse":         {0, 1},
+		"filter add":     {2, 2},
+		"filter list":    {0, 0},
+		"filter rm":      {1, 1},
+		"filter test":    {0, 1},
+		"webhook add":    {1, 1},
+		"webhook list":   {0, 0},
+		"webhook rm":     {1, 1},
+		"webhook test":   {1, 1},
+		"webhook log":    {0, 1},
+		"digest":         {0, 0},
+		"download":       {0, 0},
+	}
+
+	var check func(defs []*commandDef)
+	check = func(defs []*commandDef) {
+		for _, def := range defs {
+			if len(def.subcommands) != 0 {
+				check(def.subcommands)
+				continue
+			}
+
+			counts, ok := want[def.path()]
+			if !ok {
+				t.Errorf("%s isn't in the test", def.path())
+				continue
+			}
+			delete(want, def.path())
+
+			min, max := argCounts(def.usage)
+			if min != counts[0] || max != counts[1] {
+				t.Errorf("%s (%q) takes %d to %d arguments, want %d to %d", def.path(), def.usage, min, max, counts[0], counts[1])
+			}
+		}
+	}
+	check(c.supportedCommands)
+
+	for name := range want {
+		t.Errorf("there's no command %s", name)
+	}
+}
+
+func TestReadingAnUndefinedFlagPanics(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	cmd := command{name: "test", flags: fs}
+
+	for _, read := range []func(){
+		func() { cmd.boolFlag("force") },
+		func() { cmd.stringFlag("force") },
+		func() { cmd.boolFlag("name") },
+		func() { command{name: "raw"}.stringFlag("name") },
+	} {
+		func() {
+			defer func() {
+				r := recover()
+				if msg, ok := r.(string); !ok || !strings.Contains(msg, "--") {
+					t.Errorf("got panic %v, want one naming the flag", r)
+				}
+			}()
+			read()
+		}()
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/45uperman/gator/internal/config"
+	"github.com/google/uuid"
+)
+
+// The completion scripts all hand the words typed so far to the hidden
+// __complete command, which prints what could come next one per line.
+// --profile and --config only take effect before the command, so the
+// scripts pass any that were typed in front of __complete too.
+
+const bashCompletion = `# bash completion for gator
+_gator() {
+    local line=${COMP_LINE:0:COMP_POINT}
+    local -a words
+    read -ra words <<< "$line"
+    if [[ -z $line || $line == *[[:space:]] ]]; then
+        words+=("")
+    fi
+    local n=${#words[@]}
+    local cur=${words[n-1]}
+
+    local -a global=()
+    local i=1
+    while (( i < n - 1 )) && [[ ${words[i]} == -* ]]; do
+        case ${words[i]} in
+            --profile|-profile|--config|-config)
+                (( i + 1 < n - 1 )) || break
+                global+=("${words[i]}" "${words[i+1]}")
+                (( i += 2 )) ;;
+            --profile=*|-profile=*|--config=*|-config=*)
+                global+=("${words[i]}")
+                (( i += 1 )) ;;
+            *)
+                (( i += 1 )) ;;
+        esac
+    done
+
+    local IFS=$'\n'
+    COMPREPLY=($(gator "${global[@]}" __complete "${words[@]:1}" 2>/dev/null))
+
+    # bash treats the colon in urls as the end of a word
+    if [[ $cur == *:* && $COMP_WORDBREAKS == *:* ]]; then
+        local colon_word=${cur%"${cur##*:}"}
+        COMPREPLY=("${COMPREPLY[@]#"$colon_word"}")
+    fi
+}
+complete -o default -F _gator gator
+`
+
+const zshCompletion = `#compdef gator
+# zsh completion for gator
+_gator() {
+    local -a completions global
+    local i=2
+    while (( i < CURRENT )) && [[ $words[i] == -* ]]; do
+        case $words[i] in
+            (--profile|-profile|--config|-config)
+                (( i + 1 < CURRENT )) || break
+                global+=($words[i] $words[i+1])
+                (( i += 2 )) ;;
+            (--profile=*|-profile=*|--config=*|-config=*)
+                global+=($words[i])
+                (( i += 1 )) ;;
+            (*)
+                (( i += 1 )) ;;
+        esac
+    done
+
+    completions=(${(f)"$(gator $global __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    if (( ${#completions} )); then
+        compadd -a completions
+    else
+        _files
+    fi
+}
+
+if [[ $funcstack[1] == _gator ]]; then
+    _gator "$@"
+else
+    compdef _gator gator
+fi
+`
+
+const fishCompletion = `# fish completion for gator
+function __gator_complete
+    set -l words (commandline -opc) (commandline -ct)
+    set -l n (count $words)
+    set -l global
+    set -l i 2
+    while test $i -lt $n; and string match -q -- '-*' $words[$i]
+        switch $words[$i]
+            case --profile -profile --config -config
+                test (math $i + 1) -lt $n; or break
+                set -a global $words[$i] $words[(math $i + 1)]
+                set i (math $i + 2)
+            case '--profile=*' '-profile=*' '--config=*' '-config=*'
+                set -a global $words[$i]
+                set i (math $i + 1)
+            case '*'
+                set i (math $i + 1)
+        end
+    end
+    gator $global __complete $words[2..-1] 2>/dev/null
+end
+complete -c gator -f -a '(__gator_complete)'
+`
+
+func handlerCompletion(s *state, cmd command) error {
+	switch cmd.args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		return cmd.usageErrorf("gator can complete bash, zsh or fish, not '%s'", cmd.args[0])
+	}
+
+	return nil
+}
+
+// handlerComplete prints the ways the last of cmd.args could be completed,
+// given the words before it. Anything that goes wrong just means there's
+// nothing to suggest.
+func handlerComplete(c *commands) func(*state, command) error {
+	return func(s *state, cmd command) error {
+		if len(cmd.args) == 0 {
+			return nil
+		}
+
+		for _, candidate := range c.complete(s, cmd.args[:len(cmd.args)-1], cmd.args[len(cmd.args)-1]) {
+			fmt.Println(candidate)
+		}
+
+		return nil
+	}
+}
+
+func (c *commands) complete(s *state, words []string, current string) []string {
+	// global flags come before the command
+	for len(words) != 0 && strings.HasPrefix(words[0], "-") {
+		if needsValue(c.globalFlags, words[0]) {
+			if len(words) == 1 {
+				// current is the flag's value
+				if strings.TrimLeft(words[0], "-") == "profile" {
+					return matching(s.cfg.Profiles(), current)
+				}
+				return nil
+			}
+			words = words[1:]
+		}
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		if strings.HasPrefix(current, "-") {
+			return matching(flagNames(c.globalFlags), current)
+		}
+		aliases, _ := aliasNames(s)
+		return matching(append(commandNames(c.supportedCommands), aliases...), current)
+	}
+
+	cmd, err := c.expand(s, command{name: words[0], args: words[1:]})
+	if err != nil {
+		return nil
+	}
+	words = append([]string{cmd.name}, cmd.args...)
+
+	def := findCommand(c.supportedCommands, words[0])
+	words = words[1:]
+	for def != nil && len(def.subcommands) != 0 {
+		if len(words) == 0 {
+			return matching(commandNames(def.subcommands), current)
+		}
+		def = findCommand(def.subcommands, words[0])
+		words = words[1:]
+	}
+	if def == nil || def.rawArgs {
+		return nil
+	}
+
+	fs := def.flagSet()
+	if strings.HasPrefix(current, "-") {
+		return matching(flagNames(fs), current)
+	}
+	if len(words) != 0 && needsValue(fs, words[len(words)-1]) {
+		// leave flag values to the shell
+		return nil
+	}
+	if def.complete == nil {
+		return nil
+	}
+
+	values, err := def.complete(s, skipFlags(fs, words))
+	if err != nil {
+		return nil
+	}
+
+	return matching(values, current)
+}
+
+// skipFlags drops the flags defined by fs from words, along with their
+// values, leaving the positional arguments.
+func skipFlags(fs *flag.FlagSet, words []string) []string {
+	positional := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		if !strings.HasPrefix(words[i], "-") {
+			positional = append(positional, words[i])
+			continue
+		}
+		if needsValue(fs, words[i]) {
+			i++
+		}
+	}
+
+	return positional
+}
+
+// needsValue reports whether word is a flag whose value is the next word.
+func needsValue(fs *flag.FlagSet, word string) bool {
+	name := strings.TrimLeft(word, "-")
+	if strings.Contains(name, "=") {
+		return false
+	}
+
+	f := fs.Lookup(name)
+	if f == nil {
+		return false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return false
+	}
+
+	return true
+}
+
+func flagNames(fs *flag.FlagSet) []string {
+	names := make([]string, 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, "--"+f.Name)
+	})
+
+	return names
+}
+
+func commandNames(defs []*commandDef) []string {
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		if !def.hidden {
+			names = append(names, def.name)
+		}
+	}
+
+	return names
+}
+
+func matching(values []string, prefix string) []string {
+	matches := make([]string, 0)
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			matches = append(matches, value)
+		}
+	}
+
+	return matches
+}
+
+// completeFirst completes a command's first argument with values, and
+// leaves the rest alone.
+func completeFirst(values func(s *state) ([]string, error)) func(*state, []string) ([]string, error) {
+	return func(s *state, args []string) ([]string, error) {
+		if len(args) != 0 {
+			return nil, nil
+		}
+
+		return values(s)
+	}
+}
+
+func completeHelp(c *commands) func(*state, []string) ([]string, error) {
+	return func(s *state, args []string) ([]string, error) {
+		defs := c.supportedCommands
+		for _, name := range args {
+			def := findCommand(defs, name)
+			if def == nil {
+				return nil, nil
+			}
+			defs = def.subcommands
+		}
+
+		return commandNames(defs), nil
+	}
+}
+
+func userNames(s *state) ([]string, error) {
+	users, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Name
+	}
+
+	return names, nil
+}
+
+func feedURLs(s *state) ([]string, error) {
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, len(feeds))
+	for i, f := range feeds {
+		urls[i] = f.Url
+	}
+
+	return urls, nil
+}
+
+// followedFeedURLs returns the urls of the feeds the current user follows,
+// or with followed false, the ones they don't.
+func followedFeedURLs(followed bool) func(s *state) ([]string, error) {
+	return func(s *state) ([]string, error) {
+		feeds, err := s.db.GetFeeds(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		follows, err := s.db.GetFeedFollowsForUser(context.Background(), s.cfg.CurrentUserName)
+		if err != nil {
+			return nil, err
+		}
+
+		isFollowed := make(map[uuid.UUID]bool)
+		for _, follow := range follows {
+			isFollowed[follow.FeedID] = true
+		}
+
+		urls := make([]string, 0)
+		for _, f := range feeds {
+			if isFollowed[f.ID] == followed {
+				urls = append(urls, f.Url)
+			}
+		}
+
+		return urls, nil
+	}
+}
+
+func profileNames(s *state) ([]string, error) {
+	return s.cfg.Profiles(), nil
+}
+
+func configKeys(s *state) ([]string, error) {
+	names := make([]string, len(config.Keys))
+	for i, k := range config.Keys {
+		names[i] = k.Name
+	}
+
+	return names, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBashCompletionPassesGlobalFlags(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash isn't installed")
+	}
+
+	// this gator just says how it was run
+	bin := t.TempDir()
+	err = os.WriteFile(filepath.Join(bin, "gator"), []byte("#!/bin/sh\nprintf '%s\\n' \"$@\"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(bin, "gator.bash")
+	err = os.WriteFile(script, []byte(bashCompletion), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		line string
+		want string
+	}{
+		{"gator br", "__complete br"},
+		{"gator --profile team br", "--profile team __complete --profile team br"},
+		{"gator --config=c.json --ephemeral --profile team br", "--config=c.json --profile team __complete --config=c.json --ephemeral --profile team br"},
+		// the profile's name is still being typed
+		{"gator --profile te", "__complete --profile te"},
+	} {
+		cmd := exec.Command(bash, "-c", `. "$1"; COMP_LINE=$2; COMP_POINT=${#2}; _gator; printf '%s\n' "${COMPREPLY[@]}"`, "bash", script, tc.line)
+		cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", tc.line, err, out)
+		}
+
+		if got := strings.Join(strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"), " "); got != tc.want {
+			t.Errorf("completing %q ran gator %q, want %q", tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestCompleteOnAFirstRunPrintsOnlyCandidates(t *testing.T) {
+	for _, tc := range []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"con"}, "config\n"},
+		{[]string{"profile", "u"}, "use\n"},
+		{[]string{"--profile", ""}, "default\n"},
+		{[]string{"nonsense", ""}, ""},
+	} {
+		// a new HOME every time, so there's never a config yet
+		stdout, stderr, err := gator(t, t.TempDir(), append([]string{"__complete"}, tc.words...)...)
+		if err != nil {
+			t.Fatalf("completing %q: %v\n%s", tc.words, err, stderr)
+		}
+		if stdout != tc.want {
+			t.Errorf("completing %q printed %q, want %q", tc.words, stdout, tc.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseAge is time.ParseDuration with support for whole days, so "7d" works
+// alongside "24h" and "90m".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runEphemeral runs commands read from stdin, one per line, until stdin runs
+// out or the user types exit. If args holds a command it's run first.
+func runEphemeral(s *state, c *commands, args []string) error {
+	fmt.Println("Ephemeral mode: everything is kept in memory and lost when gator exits. Type 'exit' to quit.")
+
+	if len(args) > 0 {
+		err := c.run(s, command{name: args[0], args: args[1:]})
+		if err != nil {
+			c.reportError(os.Stdout, err)
+		}
+	}
+
+	for {
+		line, err := prompt("gator> ")
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		words, err := splitArgs(line)
+		if err != nil {
+			fmt.Printf("error: %s\n", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		if words[0] == "exit" || words[0] == "quit" {
+			return nil
+		}
+
+		err = c.run(s, command{name: words[0], args: words[1:]})
+		if err != nil {
+			c.reportError(os.Stdout, err)
+		}
+	}
+}
+
+// splitArgs breaks line into words the way a shell would, so quoted
+// arguments like "Boot.dev Blog" stay together.
+func splitArgs(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	quote := rune(0)
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"io"
+)
+
+// parseFlags lets flags appear anywhere in args, not just before the first
+// positional argument, and returns the positional arguments in order.
+// Everything after a "--" is positional.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	positional := make([]string, 0)
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed := args[:len(args)-len(fs.Args())]
+		args = fs.Args()
+		if len(parsed) != 0 && parsed[len(parsed)-1] == "--" {
+			positional = append(positional, args...)
+			break
+		}
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return positional, nil
+}
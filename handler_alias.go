@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func handlerAliasAdd(c *commands) func(*state, command) error {
+	return func(s *state, cmd command) error {
+		name := cmd.args[0]
+		if strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\"'\\") {
+			return cmd.usageErrorf("alias names can't start with '-' or contain spaces, quotes or backslashes")
+		}
+		if findCommand(c.supportedCommands, name) != nil {
+			return fmt.Errorf("there's already a command called '%s'", name)
+		}
+
+		// a single argument is a whole command line, quoted so its flags
+		// weren't taken as the alias command's
+		expansion := cmd.args[1]
+		if len(cmd.args) > 2 {
+			expansion = joinArgs(cmd.args[1:])
+		}
+
+		words, err := splitArgs(expansion)
+		if err != nil {
+			return err
+		}
+		if len(words) == 0 {
+			return cmd.usageErrorf("alias %s needs a command to stand for", name)
+		}
+		aliases := s.cfg.Aliases()
+		if findCommand(c.supportedCommands, words[0]) == nil && aliases[words[0]] == "" {
+			return fmt.Errorf("aliases have to stand for a gator command or another alias, and '%s' is neither", words[0])
+		}
+
+		old, existed := aliases[name]
+
+		// make sure the new alias doesn't end up standing for itself
+		if aliases == nil {
+			aliases = make(map[string]string)
+		}
+		aliases[name] = expansion
+		_, err = c.expandAliases(aliases, command{name: name})
+		if err != nil {
+			return err
+		}
+
+		err = s.cfg.SetAlias(name, expansion)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("'gator %s' now runs 'gator %s'\n", name, expansion)
+		if existed {
+			fmt.Printf("(it used to run 'gator %s')\n", old)
+		}
+
+		return nil
+	}
+}
+
+func handlerAliasRemove(s *state, cmd command) error {
+	err := s.cfg.RemoveAlias(cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed alias '%s'\n", cmd.args[0])
+
+	return nil
+}
+
+func handlerAliasList(s *state, cmd command) error {
+	aliases := s.cfg.Aliases()
+	if len(aliases) == 0 {
+		fmt.Println("No aliases set")
+		return nil
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Printf("%s = %s\n", name, aliases[name])
+	}
+
+	return nil
+}
+
+func aliasNames(s *state) ([]string, error) {
+	names := make([]string, 0)
+	for name := range s.cfg.Aliases() {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// joinArgs is the reverse of splitArgs, quoting any word that needs it.
+func joinArgs(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		if word != "" && !strings.ContainsAny(word, " \t\"'\\") {
+			quoted[i] = word
+			continue
+		}
+
+		word = strings.ReplaceAll(word, `\`, `\\`)
+		word = strings.ReplaceAll(word, `"`, `\"`)
+		quoted[i] = `"` + word + `"`
+	}
+
+	return strings.Join(quoted, " ")
+}
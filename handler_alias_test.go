@@ -0,0 +1,110 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	for _, tc := range []struct {
+		line string
+		want []string
+		err  bool
+	}{
+		{line: "", want: nil},
+		{line: "  browse\t 20 ", want: []string{"browse", "20"}},
+		{line: `filter add hide "/sponsored post/i"`, want: []string{"filter", "add", "hide", "/sponsored post/i"}},
+		{line: `a 'single "quotes"' "double 'quotes'"`, want: []string{"a", `single "quotes"`, `double 'quotes'`}},
+		{line: `"" ''`, want: []string{"", ""}},
+		{line: `a\ b "c\"d" 'e\f'`, want: []string{"a b", `c"d`, `e\f`}},
+		{line: `pre"quoted"post`, want: []string{"prequotedpost"}},
+		{line: `"unterminated`, err: true},
+		{line: `'unterminated`, err: true},
+	} {
+		got, err := splitArgs(tc.line)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: split into %q without an error", tc.line, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.line, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%q: got %q, want %q", tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	for _, words := range [][]string{
+		{"browse", "--category", "golang", "20"},
+		{"filter", "add", "hide", "/sponsored post/i"},
+		{"", "tab\there", `back\slash`, `"double"`, "'single'", `\"`, "ünïcode"},
+	} {
+		line := joinArgs(words)
+
+		got, err := splitArgs(line)
+		if err != nil {
+			t.Errorf("%q: joined into %q, which doesn't split: %v", words, line, err)
+			continue
+		}
+		if !slices.Equal(got, words) {
+			t.Errorf("%q: joined into %q, which splits into %q", words, line, got)
+		}
+	}
+
+	if got := joinArgs([]string{"browse", "20"}); got != "browse 20" {
+		t.Errorf("quoted words that don't need it: %q", got)
+	}
+}
+
+func TestAliasesExpand(t *testing.T) {
+	s, c := newTestState(t)
+	mustRun(t, s, c, "alias add b -- browse --full")
+	mustRun(t, s, c, "alias add b5 b 5")
+
+	for _, tc := range []struct {
+		line string
+		name string
+		args []string
+	}{
+		{"browse 3", "browse", []string{"3"}},
+		{"b 3", "browse", []string{"--full", "3"}},
+		{"b5", "browse", []string{"--full", "5"}},
+		{"unknown x", "unknown", []string{"x"}},
+	} {
+		words, err := splitArgs(tc.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := c.expand(s, command{name: words[0], args: words[1:]})
+		if err != nil {
+			t.Errorf("%s: %v", tc.line, err)
+			continue
+		}
+		if got.name != tc.name || !slices.Equal(got.args, tc.args) {
+			t.Errorf("%s: expanded to %s %q, want %s %q", tc.line, got.name, got.args, tc.name, tc.args)
+		}
+	}
+
+	// aliases can't go round in circles
+	if err := run(t, s, c, "alias add b b5"); err == nil {
+		t.Error("made b stand for b5, which stands for b")
+	}
+	if err := run(t, s, c, "alias add loop loop"); err == nil {
+		t.Error("made an alias stand for itself")
+	}
+	if err := run(t, s, c, "alias add x nothing"); err == nil {
+		t.Error("made an alias stand for something that isn't a command or alias")
+	}
+
+	// in case the config file was edited by hand
+	cycle := map[string]string{"a": "b --x", "b": "c", "c": "a"}
+	if _, err := c.expandAliases(cycle, command{name: "a"}); err == nil {
+		t.Error("expanded aliases that stand for each other")
+	}
+}
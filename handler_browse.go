@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/filter"
+	"github.com/google/uuid"
+)
+
+// browsePost is a post from a user's timeline along with its categories and
+// media, which are looked up a page at a time rather than post by post.
+type browsePost struct {
+	database.GetPostsForUserRow
+	categories []string
+	enclosures []database.PostEnclosure
+}
+
+func handlerBrowse(s *state, cmd command, user database.User) error {
+	limit := 2
+	if len(cmd.args) != 0 {
+		_, err := fmt.Sscanf(cmd.args[0], "%d", &limit)
+		if err != nil {
+			return cmd.usageErrorf("browse takes an integer as an optional argument, not '%s'", cmd.args[0])
+		}
+	}
+
+	rules, err := filtersForUser(s, user)
+	if err != nil {
+		return err
+	}
+
+	posts, err := visiblePostsForUser(s, user, limit, rules, cmd.stringFlag("category"))
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		fmt.Printf("%s - '%s'", post.FeedName, post.Title.String)
+		if post.Updated {
+			fmt.Print(" (updated)")
+		}
+		fmt.Print("\n")
+
+		if post.Author.Valid {
+			fmt.Printf("  by %s\n", post.Author.String)
+		}
+
+		if len(post.categories) != 0 {
+			fmt.Printf("  categories: %s\n", strings.Join(post.categories, ", "))
+		}
+
+		if post.Url.Valid {
+			fmt.Printf("  %s\n", post.Url.String)
+		}
+		if post.CommentsUrl.Valid {
+			fmt.Printf("  comments: %s\n", post.CommentsUrl.String)
+		}
+
+		for _, enc := range post.enclosures {
+			fmt.Printf("  media: %s", enc.Url)
+			if enc.MediaType.Valid {
+				fmt.Printf(" (%s)", enc.MediaType.String)
+			}
+			if enc.Duration.Valid {
+				fmt.Printf(" [%s]", enc.Duration.String)
+			}
+			fmt.Print("\n")
+		}
+
+		if cmd.boolFlag("full") {
+			// feeds without separate content put the whole post in the description
+			content := post.Content
+			if !content.Valid {
+				content = post.Description
+			}
+			if content.Valid {
+				fmt.Printf("\n  %s\n\n", strings.ReplaceAll(strings.TrimSpace(content.String), "\n", "\n  "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// visiblePostsForUser pages through a user's timeline until it has collected
+// limit posts that pass their filters (and are tagged with category, if one
+// is given), or runs out of posts.
+func visiblePostsForUser(s *state, user database.User, limit int, rules filter.Set, category string) ([]browsePost, error) {
+	visible := make([]browsePost, 0)
+	offset := 0
+
+	for len(visible) < limit {
+		page, err := s.db.GetPostsForUser(
+			context.Background(),
+			database.GetPostsForUserParams{
+				UserID: user.ID,
+				Limit:  int32(limit),
+				Offset: int32(offset),
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		categories, err := s.db.GetCategoriesForPostsPage(
+			context.Background(),
+			database.GetCategoriesForPostsPageParams{
+				UserID: user.ID,
+				Limit:  int32(limit),
+				Offset: int32(offset),
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		categoriesByPost := make(map[uuid.UUID][]string)
+		for _, c := range categories {
+			categoriesByPost[c.PostID] = append(categoriesByPost[c.PostID], c.Name)
+		}
+
+		enclosures, err := s.db.GetEnclosuresForPostsPage(
+			context.Background(),
+			database.GetEnclosuresForPostsPageParams{
+				UserID: user.ID,
+				Limit:  int32(limit),
+				Offset: int32(offset),
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		enclosuresByPost := make(map[uuid.UUID][]database.PostEnclosure)
+		for _, enc := range enclosures {
+			enclosuresByPost[enc.PostID] = append(enclosuresByPost[enc.PostID], enc)
+		}
+
+		for _, post := range page {
+			ok, _ := rules.Allows(post.FeedID, post.Title.String, post.Description.String)
+			if !ok || len(visible) == limit {
+				continue
+			}
+
+			if category != "" && !hasCategory(categoriesByPost[post.ID], category) {
+				continue
+			}
+
+			visible = append(visible, browsePost{
+				GetPostsForUserRow: post,
+				categories:         categoriesByPost[post.ID],
+				enclosures:         enclosuresByPost[post.ID],
+			})
+		}
+
+		if len(page) < limit {
+			break
+		}
+		offset += len(page)
+	}
+
+	return visible, nil
+}
+
+func hasCategory(categories []string, category string) bool {
+	for _, c := range categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+
+	return false
+}
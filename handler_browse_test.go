@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/memory"
+	"github.com/google/uuid"
+)
+
+// perPostLookups is a store that counts the lookups browse used to make for
+// every post it printed.
+type perPostLookups struct {
+	*memory.Store
+	n int
+}
+
+func (s *perPostLookups) GetFeed(ctx context.Context, id uuid.UUID) (database.Feed, error) {
+	s.n++
+	return s.Store.GetFeed(ctx, id)
+}
+
+func (s *perPostLookups) GetCategoriesForPost(ctx context.Context, postID uuid.UUID) ([]string, error) {
+	s.n++
+	return s.Store.GetCategoriesForPost(ctx, postID)
+}
+
+func (s *perPostLookups) GetEnclosuresForPost(ctx context.Context, postID uuid.UUID) ([]database.PostEnclosure, error) {
+	s.n++
+	return s.Store.GetEnclosuresForPost(ctx, postID)
+}
+
+func TestBrowseLooksUpPostDetailsAPageAtATime(t *testing.T) {
+	s, c := newTestState(t)
+	db := &perPostLookups{Store: s.db.(*memory.Store)}
+	s.db = db
+	alice := addUser(t, s, "alice", "")
+	logIn(t, s, alice)
+	ctx := context.Background()
+
+	mustRun(t, s, c, "addfeed podcast https://example.com/feed.xml")
+	f, err := s.db.GetFeedByURL(ctx, "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only every other episode is tagged, so browse has to page through
+	// untagged ones to find enough
+	published := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 6 {
+		post, err := s.db.CreatePost(ctx, database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Title:       sql.NullString{String: fmt.Sprintf("Episode %d", i), Valid: true},
+			PublishedAt: sql.NullTime{Time: published.Add(time.Duration(i) * time.Hour), Valid: true},
+			FeedID:      f.ID,
+			Guid:        fmt.Sprint(i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			err = s.db.CreatePostCategory(ctx, database.CreatePostCategoryParams{PostID: post.ID, Name: "interviews"})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = s.db.CreatePostEnclosure(ctx, database.CreatePostEnclosureParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			PostID:    post.ID,
+			Url:       fmt.Sprintf("https://example.com/%d.mp3", i),
+			Source:    "enclosure",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db.n = 0
+	got, err := output(t, s, c, "browse --category interviews 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ""
+	for _, i := range []int{4, 2, 0} {
+		want += fmt.Sprintf("podcast - 'Episode %d'\n  categories: interviews\n  media: https://example.com/%d.mp3\n", i, i)
+	}
+	if got != want {
+		t.Errorf("browse printed:\n%s\nwant:\n%s", got, want)
+	}
+	if db.n != 0 {
+		t.Errorf("looked up %d feeds, categories or media one post at a time", db.n)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/45uperman/gator/internal/config"
+)
+
+func handlerConfigGet(s *state, cmd command) error {
+	value, err := s.cfg.Get(cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+
+	return nil
+}
+
+func handlerConfigSet(s *state, cmd command) error {
+	err := s.cfg.Set(cmd.args[0], cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Set %s\n", cmd.args[0])
+	if env := s.cfg.OverriddenBy(cmd.args[0]); env != "" {
+		fmt.Printf("Note: %s is set, so it will be used instead\n", env)
+	}
+
+	return nil
+}
+
+func handlerConfigUnset(s *state, cmd command) error {
+	err := s.cfg.Unset(cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Unset %s\n", cmd.args[0])
+
+	return nil
+}
+
+func handlerConfigList(s *state, cmd command) error {
+	if s.cfg.Profile != config.DefaultProfile {
+		fmt.Printf("Profile: %s\n\n", s.cfg.Profile)
+	}
+
+	for _, k := range config.Keys {
+		value, err := s.cfg.Get(k.Name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s = %s\n", k.Name, k.Display(value))
+		fmt.Printf("  %s (%s)\n", k.Description, k.Type)
+		if env := s.cfg.OverriddenBy(k.Name); env != "" {
+			fmt.Printf("  overridden by %s\n", env)
+		}
+	}
+
+	return nil
+}
+
+func handlerConfigPath(s *state, cmd command) error {
+	fmt.Println(s.cfg.Path())
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/digest"
+)
+
+func handlerDigest(s *state, cmd command) error {
+	userName := cmd.stringFlag("user")
+	if userName == "" {
+		userName = s.cfg.CurrentUserName
+	}
+	sinceStr := cmd.stringFlag("since")
+	out := cmd.stringFlag("out")
+	send := cmd.boolFlag("send")
+	to := cmd.stringFlag("to")
+	if to == "" {
+		to = s.cfg.SMTP.To
+	}
+
+	since, err := parseAge(sinceStr)
+	if err != nil {
+		return cmd.usageErrorf("digest --since takes a duration ('24h', '7d' etc), not '%s'", sinceStr)
+	}
+
+	user, err := s.db.GetUser(context.Background(), userName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("cannot build a digest for user with name %s because that user does not exist", userName)
+		}
+		return err
+	}
+
+	err = authorize(s, user)
+	if err != nil {
+		return err
+	}
+
+	d, err := buildDigest(s, user, time.Now().Add(-since))
+	if err != nil {
+		return err
+	}
+
+	if out == "" && !send {
+		text, err := d.Text()
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+		return nil
+	}
+
+	from := s.cfg.SMTP.From
+	if from == "" {
+		from = "gator@localhost"
+	}
+	recipient := to
+	if recipient == "" {
+		recipient = user.Name + "@localhost"
+	}
+
+	msg, err := d.Message(from, recipient)
+	if err != nil {
+		return err
+	}
+
+	if out != "" {
+		if filepath.Ext(out) == ".mbox" {
+			err = digest.AppendMbox(out, from, d.Until, msg)
+		} else {
+			err = digest.WriteEML(out, msg)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote digest with %d posts to %s\n", d.PostCount(), out)
+	}
+
+	if send {
+		if to == "" {
+			return fmt.Errorf("digest --send requires a recipient, either with --to or smtp.to in the config")
+		}
+
+		err = digest.Send(
+			digest.SMTPConfig{
+				Host:     s.cfg.SMTP.Host,
+				Port:     s.cfg.SMTP.Port,
+				Username: s.cfg.SMTP.Username,
+				Password: s.cfg.SMTP.Password,
+			},
+			from,
+			to,
+			msg,
+		)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Sent digest with %d posts to %s\n", d.PostCount(), to)
+	}
+
+	return nil
+}
+
+func buildDigest(s *state, user database.User, since time.Time) (digest.Digest, error) {
+	rules, err := filtersForUser(s, user)
+	if err != nil {
+		return digest.Digest{}, err
+	}
+
+	posts, err := s.db.GetPostsForUserSince(
+		context.Background(),
+		database.GetPostsForUserSinceParams{
+			UserID:    user.ID,
+			CreatedAt: since,
+		},
+	)
+	if err != nil {
+		return digest.Digest{}, err
+	}
+
+	d := digest.Digest{
+		UserName: user.Name,
+		Since:    since,
+		Until:    time.Now(),
+		Feeds:    make([]digest.FeedGroup, 0),
+	}
+
+	for _, post := range posts {
+		ok, _ := rules.Allows(post.FeedID, post.Title.String, post.Description.String)
+		if !ok {
+			continue
+		}
+
+		// posts come back grouped by feed, so a new group starts whenever the feed changes
+		if len(d.Feeds) == 0 || d.Feeds[len(d.Feeds)-1].Url != post.FeedUrl {
+			d.Feeds = append(d.Feeds, digest.FeedGroup{Name: post.FeedName, Url: post.FeedUrl})
+		}
+
+		group := &d.Feeds[len(d.Feeds)-1]
+		group.Posts = append(group.Posts, digest.Post{
+			Title:       post.Title.String,
+			Url:         post.Url.String,
+			Description: post.Description.String,
+			PublishedAt: post.PublishedAt.Time,
+		})
+	}
+
+	return d, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/download"
+	"github.com/google/uuid"
+)
+
+func handlerDownload(s *state, cmd command, user database.User) error {
+	feedURL := cmd.stringFlag("feed")
+	sinceStr := cmd.stringFlag("since")
+	dir := cmd.stringFlag("dir")
+	maxSizeStr := cmd.stringFlag("max-size")
+
+	if dir == "" {
+		return cmd.usageErrorf("download requires a directory to save media in with --dir")
+	}
+
+	since, err := parseAge(sinceStr)
+	if err != nil {
+		return cmd.usageErrorf("download --since takes a duration ('24h', '7d' etc), not '%s'", sinceStr)
+	}
+
+	maxSize := int64(0)
+	if maxSizeStr != "" {
+		maxSize, err = download.ParseSize(maxSizeStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	enclosures, err := s.db.GetEnclosuresForUser(
+		context.Background(),
+		database.GetEnclosuresForUserParams{
+			UserID:    user.ID,
+			CreatedAt: time.Now().Add(-since),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	downloaded, skipped, failed := 0, 0, 0
+	for _, enc := range enclosures {
+		if feedURL != "" && enc.FeedUrl != feedURL {
+			continue
+		}
+
+		if maxSize > 0 && enc.Length.Valid && enc.Length.Int64 > maxSize {
+			fmt.Printf("Skipping %s: larger than %s\n", enc.Url, maxSizeStr)
+			skipped++
+			continue
+		}
+
+		dest, done, err := downloadPath(s, dir, enc)
+		if err != nil {
+			return err
+		}
+		if done {
+			skipped++
+			continue
+		}
+
+		err = os.MkdirAll(filepath.Dir(dest), 0755)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Downloading %s\n", enc.Url)
+		size, sum, err := download.Fetch(context.Background(), enc.Url, dest, maxSize)
+		if err != nil {
+			fmt.Printf("error downloading %s: %s\n", enc.Url, err)
+			failed++
+			continue
+		}
+
+		_, err = s.db.CreateDownload(
+			context.Background(),
+			database.CreateDownloadParams{
+				ID:          uuid.New(),
+				CreatedAt:   time.Now(),
+				EnclosureID: enc.ID,
+				Path:        dest,
+				Size:        size,
+				Sha256:      sum,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Saved %s (%d bytes, sha256 %s)\n", dest, size, sum)
+		downloaded++
+	}
+
+	fmt.Printf("\n%d downloaded, %d skipped, %d failed\n", downloaded, skipped, failed)
+
+	return nil
+}
+
+// downloadPath works out where an enclosure should be saved, and whether it
+// has already been downloaded somewhere in dir.
+func downloadPath(s *state, dir string, enc database.GetEnclosuresForUserRow) (string, bool, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false, err
+	}
+
+	downloads, err := s.db.GetDownloadsForEnclosure(context.Background(), enc.ID)
+	if err != nil {
+		return "", false, err
+	}
+	for _, existing := range downloads {
+		// the feed may have been renamed since, so look under all of dir
+		rel, err := filepath.Rel(absDir, existing.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		// files that were deleted after downloading stay deleted
+		info, err := os.Stat(existing.Path)
+		if err == nil && info.Size() != existing.Size {
+			fmt.Printf("warning: %s has changed since it was downloaded\n", existing.Path)
+		}
+
+		return existing.Path, true, nil
+	}
+
+	feedDir := filepath.Join(absDir, download.SafeName(enc.FeedName))
+	dest := filepath.Join(feedDir, download.FileName(enc.Url, enc.ID.String()))
+
+	_, err = s.db.GetDownloadByPath(context.Background(), dest)
+	if err == sql.ErrNoRows {
+		return dest, false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	// another enclosure already uses this file name
+	dest = filepath.Join(feedDir, enc.ID.String()[:8]+"-"+download.FileName(enc.Url, enc.ID.String()))
+
+	_, err = s.db.GetDownloadByPath(context.Background(), dest)
+	if err == sql.ErrNoRows {
+		return dest, false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return "", false, fmt.Errorf("%s is already used by another download", dest)
+}
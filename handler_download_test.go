@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestDownloadRemembersFilesAfterRenamingTheFeed(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "")
+	logIn(t, s, alice)
+	ctx := context.Background()
+
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write([]byte("episode"))
+	}))
+	defer srv.Close()
+
+	mustRun(t, s, c, "addfeed podcast https://example.com/feed.xml")
+	f, err := s.db.GetFeedByURL(ctx, "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := s.db.CreatePost(ctx, database.CreatePostParams{ID: uuid.New(), CreatedAt: time.Now(), UpdatedAt: time.Now(), FeedID: f.ID, Guid: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.db.CreatePostEnclosure(ctx, database.CreatePostEnclosureParams{ID: uuid.New(), CreatedAt: time.Now(), PostID: post.ID, Url: srv.URL + "/1.mp3", Source: "enclosure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	mustRun(t, s, c, "download --dir "+dir)
+	mustRun(t, s, c, "renamefeed https://example.com/feed.xml show")
+	mustRun(t, s, c, "download --dir "+dir)
+
+	if fetches != 1 {
+		t.Errorf("downloaded the episode %d times, want once", fetches)
+	}
+}
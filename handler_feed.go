@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+)
+
+// ownedFeed looks up a feed by url and makes sure user is the one who added it.
+func ownedFeed(s *state, feedURL string, user database.User) (database.Feed, error) {
+	f, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.Feed{}, fmt.Errorf("there is no feed with url %s", feedURL)
+		}
+		return database.Feed{}, err
+	}
+
+	if f.UserID != user.ID {
+		return database.Feed{}, fmt.Errorf("feed '%s' was added by another user, so only they can change it", f.Name)
+	}
+
+	return f, nil
+}
+
+func handlerRemoveFeed(s *state, cmd command, user database.User) error {
+	f, err := ownedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	followers, err := s.db.CountFeedFollowers(context.Background(), f.ID)
+	if err != nil {
+		return err
+	}
+
+	isFollowing := int64(0)
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.Name)
+	if err != nil {
+		return err
+	}
+	for _, follow := range follows {
+		if follow.FeedID == f.ID {
+			isFollowing = 1
+		}
+	}
+
+	otherFollowers := followers - isFollowing
+	if otherFollowers > 0 && !cmd.boolFlag("force") {
+		return fmt.Errorf(
+			"feed '%s' is followed by %d other user(s); run removefeed again with --force to remove it for everyone",
+			f.Name,
+			otherFollowers,
+		)
+	}
+
+	posts, err := s.db.CountPostsForFeed(context.Background(), f.ID)
+	if err != nil {
+		return err
+	}
+
+	// follows, posts, filters and webhooks for the feed go with it
+	err = s.db.DeleteFeed(context.Background(), f.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"Removed feed '%s' along with its %d post(s) and %d follow(s)\n",
+		f.Name,
+		posts,
+		followers,
+	)
+
+	return nil
+}
+
+func handlerEditFeed(s *state, cmd command, user database.User) error {
+	newURL := cmd.stringFlag("url")
+	newName := cmd.stringFlag("name")
+	if newURL == "" && newName == "" {
+		return cmd.usageErrorf("editfeed requires --url and/or --name to change")
+	}
+
+	f, err := ownedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	params := database.UpdateFeedParams{
+		Name:      f.Name,
+		Url:       f.Url,
+		UpdatedAt: time.Now(),
+		ID:        f.ID,
+	}
+	if newName != "" {
+		params.Name = newName
+	}
+	if newURL != "" {
+		if newURL != f.Url {
+			_, err := s.db.GetFeedByURL(context.Background(), newURL)
+			if err == nil {
+				return fmt.Errorf("there is already a feed with url %s", newURL)
+			}
+			if err != sql.ErrNoRows {
+				return err
+			}
+		}
+		params.Url = newURL
+	}
+
+	updated, err := s.db.UpdateFeed(context.Background(), params)
+	if err != nil {
+		return err
+	}
+
+	if updated.Url != f.Url {
+		// the title, site and so on came from the old url, and the next fetch
+		// fills them in again from the new one
+		err = s.db.UpdateFeedMetadata(context.Background(), database.UpdateFeedMetadataParams{ID: f.ID})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Updated feed '%s'\n", updated.Name)
+	fmt.Printf("  Name: %v\n  Url: %v\n", updated.Name, updated.Url)
+
+	return nil
+}
+
+func handlerRenameFeed(s *state, cmd command, user database.User) error {
+	f, err := ownedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.db.UpdateFeed(
+		context.Background(),
+		database.UpdateFeedParams{
+			Name:      cmd.args[1],
+			Url:       f.Url,
+			UpdatedAt: time.Now(),
+			ID:        f.ID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed feed '%s' to '%s'\n", f.Name, updated.Name)
+
+	return nil
+}
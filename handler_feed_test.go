@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/45uperman/gator/internal/database"
+)
+
+func TestAddFeedFollowsIt(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "")
+	logIn(t, s, alice)
+
+	mustRun(t, s, c, "addfeed blog https://example.com/feed.xml")
+
+	f, err := s.db.GetFeedByURL(context.Background(), "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.UserID != alice.ID {
+		t.Errorf("feed belongs to %v, want alice", f.UserID)
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 || follows[0].FeedName != "blog" {
+		t.Errorf("alice follows %+v, want just blog", follows)
+	}
+
+	mustRun(t, s, c, "unfollow https://example.com/feed.xml")
+
+	n, err := s.db.CountFeedFollowsForUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("alice still follows %d feed(s) after unfollowing", n)
+	}
+}
+
+func TestOnlyTheOwnerCanChangeAFeed(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "")
+	bob := addUser(t, s, "bob", "")
+	logIn(t, s, alice)
+	mustRun(t, s, c, "addfeed blog https://example.com/feed.xml")
+
+	logIn(t, s, bob)
+	mustRun(t, s, c, "follow https://example.com/feed.xml")
+	for _, line := range []string{
+		"editfeed --name mine https://example.com/feed.xml",
+		"renamefeed https://example.com/feed.xml mine",
+		"removefeed --force https://example.com/feed.xml",
+	} {
+		if err := run(t, s, c, line); err == nil {
+			t.Errorf("bob ran %q on alice's feed", line)
+		}
+	}
+
+	f, err := s.db.GetFeedByURL(context.Background(), "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "blog" {
+		t.Errorf("feed is called %q, want blog", f.Name)
+	}
+
+	if err := run(t, s, c, "editfeed --name mine https://example.com/other.xml"); err == nil {
+		t.Error("edited a feed that doesn't exist")
+	}
+}
+
+func TestRemoveFeedNeedsForceWhenOthersFollowIt(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "")
+	bob := addUser(t, s, "bob", "")
+	logIn(t, s, bob)
+	mustRun(t, s, c, "addfeed blog https://example.com/feed.xml")
+	logIn(t, s, alice)
+	mustRun(t, s, c, "follow https://example.com/feed.xml")
+	logIn(t, s, bob)
+
+	if err := run(t, s, c, "removefeed https://example.com/feed.xml"); err == nil {
+		t.Fatal("removed a feed alice follows without --force")
+	}
+	if _, err := s.db.GetFeedByURL(context.Background(), "https://example.com/feed.xml"); err != nil {
+		t.Fatalf("feed is gone: %v", err)
+	}
+
+	mustRun(t, s, c, "removefeed --force https://example.com/feed.xml")
+
+	if _, err := s.db.GetFeedByURL(context.Background(), "https://example.com/feed.xml"); err != sql.ErrNoRows {
+		t.Errorf("feed is still there: %v", err)
+	}
+	n, err := s.db.CountFeedFollowsForUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("alice still follows %d feed(s)", n)
+	}
+}
+
+func TestEditFeedURLForgetsTheOldMetadata(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "")
+	logIn(t, s, alice)
+	ctx := context.Background()
+	mustRun(t, s, c, "addfeed blog https://example.com/feed.xml")
+
+	f, err := s.db.GetFeedByURL(ctx, "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	title := sql.NullString{String: "Example", Valid: true}
+	err = s.db.UpdateFeedMetadata(ctx, database.UpdateFeedMetadataParams{
+		Title:       title,
+		SiteUrl:     sql.NullString{String: "https://example.com", Valid: true},
+		Description: sql.NullString{String: "An example", Valid: true},
+		ID:          f.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustRun(t, s, c, "editfeed --name renamed https://example.com/feed.xml")
+	f, err = s.db.GetFeed(ctx, f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title != title {
+		t.Errorf("renaming cleared the title: %+v", f)
+	}
+
+	mustRun(t, s, c, "editfeed --url https://example.org/feed.xml https://example.com/feed.xml")
+	f, err = s.db.GetFeed(ctx, f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title.Valid || f.SiteUrl.Valid || f.Description.Valid {
+		t.Errorf("new url kept the old url's metadata: %+v", f)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/filter"
+	"github.com/google/uuid"
+)
+
+func handlerFilterAdd(s *state, cmd command, user database.User) error {
+	feedURL := cmd.stringFlag("feed")
+	field := cmd.stringFlag("field")
+
+	action, pattern := cmd.args[0], cmd.args[1]
+	if !filter.ValidAction(action) {
+		return cmd.usageErrorf("filter action must be 'hide' or 'only', not '%s'", action)
+	}
+	if !filter.ValidField(field) {
+		return cmd.usageErrorf("filter field must be 'title', 'description' or 'any', not '%s'", field)
+	}
+
+	_, err := filter.ParsePattern(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid filter pattern '%s': %w", pattern, err)
+	}
+
+	feedID := uuid.NullUUID{}
+	if feedURL != "" {
+		f, err := s.db.GetFeedByURL(context.Background(), feedURL)
+		if err != nil {
+			return err
+		}
+		feedID = uuid.NullUUID{UUID: f.ID, Valid: true}
+	}
+
+	_, err = s.db.CreateFilter(
+		context.Background(),
+		database.CreateFilterParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feedID,
+			Action:    action,
+			Field:     field,
+			Pattern:   pattern,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added filter: %s posts where %s matches '%s'\n", action, field, pattern)
+
+	return nil
+}
+
+func handlerFilterList(s *state, cmd command, user database.User) error {
+	filters, err := s.db.GetFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(filters) == 0 {
+		fmt.Println("No filters set")
+		return nil
+	}
+
+	for i, f := range filters {
+		desc, err := describeFilter(s, f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d. %s\n", i+1, desc)
+	}
+
+	return nil
+}
+
+func handlerFilterRemove(s *state, cmd command, user database.User) error {
+	n, err := strconv.Atoi(cmd.args[0])
+	if err != nil {
+		return cmd.usageErrorf("filter rm takes the number of a filter, not '%s'", cmd.args[0])
+	}
+
+	filters, err := s.db.GetFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > len(filters) {
+		return fmt.Errorf("no filter with number %d", n)
+	}
+
+	f := filters[n-1]
+	err = s.db.DeleteFilter(
+		context.Background(),
+		database.DeleteFilterParams{
+			ID:     f.ID,
+			UserID: user.ID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	desc, err := describeFilter(s, f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed filter: %s\n", desc)
+
+	return nil
+}
+
+func handlerFilterTest(s *state, cmd command, user database.User) error {
+	limit := 20
+	if len(cmd.args) != 0 {
+		_, err := fmt.Sscanf(cmd.args[0], "%d", &limit)
+		if err != nil {
+			return cmd.usageErrorf("filter test takes an integer as an optional argument, not '%s'", cmd.args[0])
+		}
+	}
+
+	filters, err := s.db.GetFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	rules, err := filter.Compile(filters)
+	if err != nil {
+		return err
+	}
+
+	posts, err := s.db.GetPostsForUser(
+		context.Background(),
+		database.GetPostsForUserParams{
+			UserID: user.ID,
+			Limit:  int32(limit),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		ok, hiddenBy := rules.Allows(post.FeedID, post.Title.String, post.Description.String)
+		if ok {
+			fmt.Printf("  shown  '%s'\n", post.Title.String)
+			continue
+		}
+
+		for i, f := range filters {
+			if f.ID == hiddenBy.ID {
+				fmt.Printf("  hidden '%s' (filter %d)\n", post.Title.String, i+1)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func describeFilter(s *state, f database.Filter) (string, error) {
+	scope := "all feeds"
+	if f.FeedID.Valid {
+		feed, err := s.db.GetFeed(context.Background(), f.FeedID.UUID)
+		if err != nil {
+			return "", err
+		}
+		scope = fmt.Sprintf("feed '%s'", feed.Name)
+	}
+
+	return fmt.Sprintf("%s posts where %s matches '%s' from %s", f.Action, f.Field, f.Pattern, scope), nil
+}
+
+func filtersForUser(s *state, user database.User) (filter.Set, error) {
+	filters, err := s.db.GetFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return filter.Set{}, err
+	}
+
+	return filter.Compile(filters)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/45uperman/gator/internal/database"
+)
+
+// handlerMigrate runs one of the migrate functions against the database.
+func handlerMigrate(run func(db *sql.DB, dialect string) error) func(*state, command) error {
+	return func(s *state, cmd command) error {
+		if s.conn == nil {
+			return fmt.Errorf("there's no database to migrate in ephemeral mode")
+		}
+
+		return run(s.conn, database.Dialect(s.cfg.DBURL))
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/45uperman/gator/internal/config"
+)
+
+func handlerProfileList(s *state, cmd command) error {
+	active := s.cfg.ActiveProfile()
+
+	for _, name := range s.cfg.Profiles() {
+		p := s.cfg.GetProfile(name)
+
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+
+		user := p.CurrentUserName
+		if user == "" {
+			user = "nobody"
+		}
+
+		fmt.Printf("%s %s: %s (logged in as %s)", marker, name, config.RedactURL(p.DBURL), user)
+		if name == s.cfg.Profile && name != active {
+			fmt.Print(" (in use)")
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func handlerProfileUse(s *state, cmd command) error {
+	err := s.cfg.UseProfile(cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Now using profile '%s'\n", cmd.args[0])
+
+	return nil
+}
+
+func handlerProfileAdd(s *state, cmd command) error {
+	err := s.cfg.AddProfile(cmd.args[0], cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added profile '%s'. Switch to it with 'gator profile use %s', or try it with 'gator --profile %s <command>'.\n", cmd.args[0], cmd.args[0], cmd.args[0])
+
+	return nil
+}
+
+func handlerProfileRemove(s *state, cmd command) error {
+	wasActive := s.cfg.ActiveProfile() == cmd.args[0]
+
+	err := s.cfg.RemoveProfile(cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed profile '%s'\n", cmd.args[0])
+	if wasActive {
+		fmt.Printf("Now using profile '%s'\n", s.cfg.ActiveProfile())
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestProfileAddChecksTheURL(t *testing.T) {
+	s, c := newTestState(t)
+
+	if err := run(t, s, c, "profile add team not-a-url"); err == nil {
+		t.Error("added a profile without a database url")
+	}
+	if p := s.cfg.Profiles(); len(p) != 1 {
+		t.Errorf("got profiles %q, want only the default one", p)
+	}
+
+	mustRun(t, s, c, "profile add team sqlite://team.db")
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/45uperman/gator/internal/database"
+)
+
+type purgeCounts struct {
+	users     int64
+	feeds     int64
+	follows   int64
+	posts     int64
+	revisions int64
+	media     int64
+	downloads int64
+	filters   int64
+	webhooks  int64
+
+	// the filters and webhooks that belong to a feed rather than a user
+	feedFilters  int64
+	feedWebhooks int64
+}
+
+// describe lists the nonzero counts, e.g. "2 feed(s), 1 follow(s) and 4 post(s)"
+func (c purgeCounts) describe() string {
+	parts := []string{}
+	for _, part := range []struct {
+		n    int64
+		noun string
+	}{
+		{c.users, "user(s)"},
+		{c.feeds, "feed(s)"},
+		{c.follows, "follow(s)"},
+		{c.posts, "post(s)"},
+		{c.revisions, "post revision(s)"},
+		{c.media, "media item(s)"},
+		{c.downloads, "download record(s)"},
+		{c.filters, "filter(s)"},
+		{c.webhooks, "webhook(s)"},
+	} {
+		if part.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", part.n, part.noun))
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return "nothing"
+	case 1:
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+func handlerPurge(s *state, cmd command) error {
+	posts := cmd.boolFlag("posts")
+	feeds := cmd.boolFlag("feeds")
+	users := cmd.boolFlag("users")
+	all := cmd.boolFlag("all")
+	backup := cmd.stringFlag("backup")
+
+	if !posts && !feeds && !users && !all {
+		return cmd.usageErrorf("purge requires at least one of --posts, --feeds, --users or --all")
+	}
+
+	total, err := countForPurge(s)
+	if err != nil {
+		return err
+	}
+
+	// deletes cascade, so each level takes everything below it along
+	doomed := purgeCounts{}
+	switch {
+	case users || all:
+		doomed = total
+	case feeds:
+		doomed = purgeCounts{
+			feeds:     total.feeds,
+			follows:   total.follows,
+			posts:     total.posts,
+			revisions: total.revisions,
+			media:     total.media,
+			downloads: total.downloads,
+			// filters and webhooks for every feed stay
+			filters:  total.feedFilters,
+			webhooks: total.feedWebhooks,
+		}
+	case posts:
+		doomed = purgeCounts{
+			posts:     total.posts,
+			revisions: total.revisions,
+			media:     total.media,
+			downloads: total.downloads,
+		}
+	}
+
+	fmt.Printf("This will delete %s.\n", doomed.describe())
+	if doomed.downloads > 0 {
+		fmt.Println("Downloaded files are left on disk.")
+	}
+
+	if cmd.boolFlag("dry-run") {
+		return nil
+	}
+
+	// whatever the level, the data belongs to every user, so every user with
+	// a password has to agree to it going
+	everyone, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, user := range everyone {
+		err = authorize(s, user)
+		if err != nil {
+			return fmt.Errorf("purge cancelled: %w", err)
+		}
+	}
+
+	if !cmd.boolFlag("yes") {
+		ok, err := confirm("This cannot be undone.", "purge")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("purge cancelled")
+		}
+	}
+
+	if backup != "" {
+		err = backupDatabase(s, backup)
+		if err != nil {
+			return fmt.Errorf("backup failed, nothing was deleted: %w", err)
+		}
+		fmt.Printf("Backed up database to %s\n", backup)
+	}
+
+	switch {
+	case users || all:
+		err = s.db.ResetUsers(context.Background())
+	case feeds:
+		err = s.db.DeleteAllFeeds(context.Background())
+	case posts:
+		err = s.db.DeleteAllPosts(context.Background())
+	}
+	if err != nil {
+		return err
+	}
+
+	if doomed.users > 0 {
+		err = s.cfg.SetUser("", "")
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Purge complete")
+
+	return nil
+}
+
+func countForPurge(s *state) (purgeCounts, error) {
+	var counts purgeCounts
+	var err error
+
+	counts.users, err = s.db.CountUsers(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+
+	counts.feeds, err = s.db.CountFeeds(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+
+	counts.follows, err = s.db.CountFeedFollows(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+
+	counts.posts, err = s.db.CountPosts(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+
+	counts.revisions, err = s.db.CountPostRevisions(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+
+	counts.media, err = s.db.CountPostEnclosures(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+
+	counts.downloads, err = s.db.CountDownloads(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+
+	filters, err := s.db.CountFilters(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+	counts.filters, counts.feedFilters = filters.Total, filters.ForFeeds
+
+	webhooks, err := s.db.CountWebhooks(context.Background())
+	if err != nil {
+		return purgeCounts{}, err
+	}
+	counts.webhooks, counts.feedWebhooks = webhooks.Total, webhooks.ForFeeds
+
+	return counts, nil
+}
+
+func backupDatabase(s *state, path string) error {
+	if s.conn == nil {
+		return fmt.Errorf("there's no database to back up in ephemeral mode")
+	}
+
+	if database.Dialect(s.cfg.DBURL) == database.DialectSQLite {
+		// VACUUM INTO writes a consistent copy of the whole database file
+		_, err := s.conn.ExecContext(context.Background(), "VACUUM INTO ?", path)
+		return err
+	}
+
+	dump := exec.Command("pg_dump", "--dbname", s.cfg.DBURL, "--file", path)
+	dump.Stderr = os.Stderr
+
+	err := dump.Run()
+	if err != nil {
+		return fmt.Errorf("pg_dump: %w", err)
+	}
+
+	return nil
+}
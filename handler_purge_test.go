@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPurgeUsersNeedsTheirPasswords(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "password1")
+	addUser(t, s, "bob", "password2")
+	logIn(t, s, alice)
+
+	if err := run(t, s, c, "purge --users --yes"); err == nil {
+		t.Error("purged bob without their password")
+	}
+
+	n, err := s.db.CountUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("%d user(s) left, want 2", n)
+	}
+}
+
+func TestPurgePostsNeedsEveryonesPasswords(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "password1")
+	addUser(t, s, "bob", "password2")
+	logIn(t, s, alice)
+
+	// bob's password is wrong, so the posts stay
+	input := stdin
+	stdin = bufio.NewReader(strings.NewReader("password1\n"))
+	defer func() { stdin = input }()
+
+	if err := run(t, s, c, "purge --posts --yes"); err == nil {
+		t.Error("purged posts without bob's password")
+	}
+}
+
+func TestPurgeCountsEverythingItDeletes(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "")
+	logIn(t, s, alice)
+
+	mustRun(t, s, c, "addfeed blog https://example.com/feed.xml")
+	mustRun(t, s, c, "filter add hide sponsored")
+	mustRun(t, s, c, "filter add only go --feed https://example.com/feed.xml")
+	mustRun(t, s, c, "webhook add http://localhost:8080/hook --feed https://example.com/feed.xml")
+
+	tests := []struct {
+		level string
+		want  string
+	}{
+		{"--posts", "This will delete nothing.\n"},
+		{"--feeds", "This will delete 1 feed(s), 1 follow(s), 1 filter(s) and 1 webhook(s).\n"},
+		{"--all", "This will delete 1 user(s), 1 feed(s), 1 follow(s), 2 filter(s) and 1 webhook(s).\n"},
+	}
+	for _, tt := range tests {
+		got, err := output(t, s, c, "purge --dry-run "+tt.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("purge %s printed %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
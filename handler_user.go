@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+)
+
+func handlerDeleteUser(s *state, cmd command) error {
+	transferTo := cmd.stringFlag("transfer-to")
+
+	user, err := s.db.GetUser(context.Background(), cmd.args[0])
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("cannot delete user with name %s because that user does not exist", cmd.args[0])
+		}
+		return err
+	}
+
+	err = authorize(s, user)
+	if err != nil {
+		return err
+	}
+
+	var heir database.User
+	if transferTo != "" {
+		heir, err = s.db.GetUser(context.Background(), transferTo)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("cannot transfer feeds to user with name %s because that user does not exist", transferTo)
+			}
+			return err
+		}
+		if heir.ID == user.ID {
+			return fmt.Errorf("cannot transfer feeds from a user to themselves")
+		}
+	}
+
+	ownedFeeds, err := s.db.CountFeedsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.boolFlag("yes") {
+		warning := fmt.Sprintf("This will delete user '%s' along with their follows, filters and webhooks.", user.Name)
+		if ownedFeeds > 0 {
+			if transferTo != "" {
+				warning += fmt.Sprintf("\nTheir %d feed(s) will be transferred to '%s'.", ownedFeeds, heir.Name)
+			} else {
+				warning += fmt.Sprintf(
+					"\nTheir %d feed(s) and all of those feeds' posts will be deleted too (use --transfer-to to keep them).",
+					ownedFeeds,
+				)
+			}
+		}
+
+		ok, err := confirm(warning, user.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("deleteuser cancelled")
+		}
+	}
+
+	// together, so a failed delete doesn't leave the user behind without
+	// their feeds
+	err = s.db.InTx(context.Background(), func(db database.Store) error {
+		if transferTo != "" {
+			err := db.TransferFeeds(
+				context.Background(),
+				database.TransferFeedsParams{
+					UserID:    heir.ID,
+					UpdatedAt: time.Now(),
+					UserID_2:  user.ID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return db.DeleteUser(context.Background(), user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.cfg.ForgetUser(user.Name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted user: %s\n", user.Name)
+
+	return nil
+}
+
+func handlerRenameUser(s *state, cmd command) error {
+	oldName, newName := cmd.args[0], cmd.args[1]
+
+	user, err := s.db.GetUser(context.Background(), oldName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("cannot rename user with name %s because that user does not exist", oldName)
+		}
+		return err
+	}
+
+	err = authorize(s, user)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.GetUser(context.Background(), newName)
+	if err == nil {
+		return fmt.Errorf("cannot rename user to %s because that user already exists", newName)
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	_, err = s.db.RenameUser(
+		context.Background(),
+		database.RenameUserParams{
+			Name:      newName,
+			UpdatedAt: time.Now(),
+			ID:        user.ID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = s.cfg.RenameUser(oldName, newName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed user '%s' to '%s'\n", oldName, newName)
+
+	return nil
+}
+
+func handlerWhoami(s *state, cmd command, user database.User) error {
+	follows, err := s.db.CountFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	feeds, err := s.db.CountFeedsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	posts, err := s.db.CountPostsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n\n", user.Name)
+	fmt.Printf(
+		"  ID: %v\n  CreatedAt: %v\n  Following: %d feed(s)\n  Added: %d feed(s)\n  Posts: %d\n",
+		user.ID,
+		user.CreatedAt,
+		follows,
+		feeds,
+		posts,
+	)
+
+	return nil
+}
+
+func handlerPasswd(s *state, cmd command, user database.User) error {
+	err := authenticate(user)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetUserPassword(
+		context.Background(),
+		database.SetUserPasswordParams{
+			PasswordHash: passwordHash,
+			UpdatedAt:    time.Now(),
+			ID:           user.ID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// logins made with the old password don't count any more
+	err = s.db.DeleteSessionsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	// a user from GATOR_USER isn't logged in, so there's no session to renew
+	if !s.cfg.UserFromEnv() {
+		user.PasswordHash = passwordHash
+		token, err := startSession(s, user)
+		if err != nil {
+			return err
+		}
+
+		err = s.cfg.SetUser(user.Name, token)
+		if err != nil {
+			return err
+		}
+	}
+
+	if passwordHash.Valid {
+		fmt.Printf("Changed password for user: %s\n", user.Name)
+	} else {
+		fmt.Printf("Removed password for user: %s\n", user.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/memory"
+	"github.com/google/uuid"
+)
+
+func TestDeleteUserTransfersFeeds(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "")
+	bob := addUser(t, s, "bob", "")
+	logIn(t, s, bob)
+	mustRun(t, s, c, "addfeed blog https://example.com/feed.xml")
+
+	mustRun(t, s, c, "deleteuser --yes --transfer-to alice bob")
+
+	f, err := s.db.GetFeedByURL(context.Background(), "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.UserID != alice.ID {
+		t.Errorf("feed belongs to %v, want alice", f.UserID)
+	}
+
+	_, err = s.db.GetUserByID(context.Background(), bob.ID)
+	if err != sql.ErrNoRows {
+		t.Errorf("bob is still around: %v", err)
+	}
+	if s.cfg.CurrentUserName != "" {
+		t.Errorf("still logged in as %q", s.cfg.CurrentUserName)
+	}
+}
+
+// undeletableUsers is a store that can't delete users.
+type undeletableUsers struct {
+	*memory.Store
+}
+
+func (s undeletableUsers) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return errors.New("can't delete users")
+}
+
+func (s undeletableUsers) InTx(ctx context.Context, fn func(database.Store) error) error {
+	return s.Store.InTx(ctx, func(database.Store) error { return fn(s) })
+}
+
+func TestDeleteUserKeepsTheirFeedsIfTheyCantBeDeleted(t *testing.T) {
+	s, c := newTestState(t)
+	addUser(t, s, "alice", "")
+	bob := addUser(t, s, "bob", "")
+	logIn(t, s, bob)
+	mustRun(t, s, c, "addfeed blog https://example.com/feed.xml")
+	s.db = undeletableUsers{s.db.(*memory.Store)}
+
+	if err := run(t, s, c, "deleteuser --yes --transfer-to alice bob"); err == nil {
+		t.Fatal("deleted bob")
+	}
+
+	f, err := s.db.GetFeedByURL(context.Background(), "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.UserID != bob.ID {
+		t.Errorf("feed went to %v, want it left with bob", f.UserID)
+	}
+}
+
+func TestRenameUserKeepsThemLoggedIn(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "password1")
+	logIn(t, s, alice)
+
+	mustRun(t, s, c, "renameuser alice al")
+
+	if s.cfg.CurrentUserName != "al" {
+		t.Errorf("logged in as %q, want al", s.cfg.CurrentUserName)
+	}
+	mustRun(t, s, c, "whoami")
+}
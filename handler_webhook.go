@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/filter"
+	"github.com/45uperman/gator/internal/webhook"
+	"github.com/google/uuid"
+)
+
+func handlerWebhookAdd(s *state, cmd command, user database.User) error {
+	feedURL := cmd.stringFlag("feed")
+	keyword := cmd.stringFlag("keyword")
+
+	feedID := uuid.NullUUID{}
+	if feedURL != "" {
+		f, err := s.db.GetFeedByURL(context.Background(), feedURL)
+		if err != nil {
+			return err
+		}
+		feedID = uuid.NullUUID{UUID: f.ID, Valid: true}
+	}
+
+	kw := sql.NullString{}
+	if keyword != "" {
+		_, err := filter.ParsePattern(keyword)
+		if err != nil {
+			return fmt.Errorf("invalid keyword pattern '%s': %w", keyword, err)
+		}
+		kw = sql.NullString{String: keyword, Valid: true}
+	}
+
+	hook, err := s.db.CreateWebhook(
+		context.Background(),
+		database.CreateWebhookParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feedID,
+			Url:       cmd.args[0],
+			Keyword:   kw,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	desc, err := describeWebhook(s, hook)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added webhook: %s\n", desc)
+
+	return nil
+}
+
+func handlerWebhookList(s *state, cmd command, user database.User) error {
+	hooks, err := s.db.GetWebhooksForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(hooks) == 0 {
+		fmt.Println("No webhooks set")
+		return nil
+	}
+
+	for i, hook := range hooks {
+		desc, err := describeWebhook(s, hook)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d. %s\n", i+1, desc)
+	}
+
+	return nil
+}
+
+func handlerWebhookRemove(s *state, cmd command, user database.User) error {
+	hook, err := webhookByNumber(s, cmd, user)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.DeleteWebhook(
+		context.Background(),
+		database.DeleteWebhookParams{
+			ID:     hook.ID,
+			UserID: user.ID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed webhook for %s\n", hook.Url)
+
+	return nil
+}
+
+func handlerWebhookTest(s *state, cmd command, user database.User) error {
+	hook, err := webhookByNumber(s, cmd, user)
+	if err != nil {
+		return err
+	}
+
+	payload := webhook.Payload{
+		Event: "test",
+		Post: webhook.PostPayload{
+			Title:       "Test post from gator",
+			Description: fmt.Sprintf("This is a test delivery for user '%s'", user.Name),
+		},
+	}
+
+	status, err := webhook.Send(context.Background(), hook.Url, payload)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Test delivery to %s succeeded with status %d\n", hook.Url, status)
+
+	return nil
+}
+
+func handlerWebhookLog(s *state, cmd command, user database.User) error {
+	limit := 20
+	if len(cmd.args) != 0 {
+		_, err := fmt.Sscanf(cmd.args[0], "%d", &limit)
+		if err != nil {
+			return cmd.usageErrorf("webhook log takes an integer as an optional argument, not '%s'", cmd.args[0])
+		}
+	}
+
+	deliveries, err := s.db.GetWebhookDeliveriesForUser(
+		context.Background(),
+		database.GetWebhookDeliveriesForUserParams{
+			UserID: user.ID,
+			Limit:  int32(limit),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		result := "ok"
+		if !d.Succeeded {
+			result = "failed: " + d.Error.String
+		}
+		fmt.Printf(
+			"%s  %s  post %v  attempt %d  %s\n",
+			d.CreatedAt.Format(time.DateTime),
+			d.WebhookUrl,
+			d.PostID,
+			d.Attempt,
+			result,
+		)
+	}
+
+	return nil
+}
+
+func webhookByNumber(s *state, cmd command, user database.User) (database.Webhook, error) {
+	n, err := strconv.Atoi(cmd.args[0])
+	if err != nil {
+		return database.Webhook{}, cmd.usageErrorf("%s takes the number of a webhook, not '%s'", cmd.name, cmd.args[0])
+	}
+
+	hooks, err := s.db.GetWebhooksForUser(context.Background(), user.ID)
+	if err != nil {
+		return database.Webhook{}, err
+	}
+	if n < 1 || n > len(hooks) {
+		return database.Webhook{}, fmt.Errorf("no webhook with number %d", n)
+	}
+
+	return hooks[n-1], nil
+}
+
+func describeWebhook(s *state, hook database.Webhook) (string, error) {
+	scope := "all followed feeds"
+	if hook.FeedID.Valid {
+		f, err := s.db.GetFeed(context.Background(), hook.FeedID.UUID)
+		if err != nil {
+			return "", err
+		}
+		scope = fmt.Sprintf("feed '%s'", f.Name)
+	}
+
+	desc := fmt.Sprintf("%s for new posts from %s", hook.Url, scope)
+	if hook.Keyword.Valid {
+		desc += fmt.Sprintf(" matching '%s'", hook.Keyword.String)
+	}
+
+	return desc, nil
+}
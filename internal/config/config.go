@@ -2,41 +2,401 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 )
 
+// DefaultProfile is the name of the settings at the top level of the config
+// file, used when no other profile is selected.
+const DefaultProfile = "default"
+
+// Config holds the settings gator runs with: those of the selected profile,
+// with any environment overrides on top.
 type Config struct {
+	DBURL           string
+	CurrentUserName string
+	// SessionToken proves CurrentUserName logged in with their password
+	SessionToken string
+	SMTP         SMTP
+	// Profile is the name of the profile in use
+	Profile string
+
+	// path is the file the config was read from, and where it gets written
+	path     string
+	file     file
+	detached bool
+	// userFromEnv is set when GATOR_USER picked CurrentUserName
+	userFromEnv bool
+}
+
+// file is the layout of the config file.
+type file struct {
+	DBURL           string             `json:"db_url"`
+	CurrentUserName string             `json:"current_user_name"`
+	SessionToken    string             `json:"session_token,omitempty"`
+	Profile         string             `json:"profile,omitempty"`
+	Profiles        map[string]Profile `json:"profiles,omitempty"`
+	SMTP            SMTP               `json:"smtp,omitzero"`
+	Aliases         map[string]string  `json:"aliases,omitempty"`
+}
+
+// Profile is a database and the user logged in to it.
+type Profile struct {
 	DBURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	SessionToken    string `json:"session_token,omitempty"`
 }
 
-func (c Config) SetUser(username string) error {
+type SMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// SetUser logs username in, along with the token of their session if they
+// have a password.
+func (c *Config) SetUser(username, sessionToken string) error {
+	err := c.update(func(f *file) error {
+		p := f.profile(c.Profile)
+		p.CurrentUserName = username
+		p.SessionToken = sessionToken
+		f.setProfile(c.Profile, p)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
 	c.CurrentUserName = username
+	c.SessionToken = sessionToken
+
+	return nil
+}
+
+// RenameUser keeps the user logged in when they're renamed from oldName to
+// newName.
+func (c *Config) RenameUser(oldName, newName string) error {
+	renamed := c.CurrentUserName == oldName
+
+	err := c.update(func(f *file) error {
+		p := f.profile(c.Profile)
+		if p.CurrentUserName == oldName {
+			p.CurrentUserName = newName
+			f.setProfile(c.Profile, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// a user from GATOR_USER is renamed for the rest of this run only
+	if renamed {
+		c.CurrentUserName = newName
+	}
+
+	return nil
+}
+
+// ForgetUser logs out username, who no longer exists.
+func (c *Config) ForgetUser(username string) error {
+	forgotten := c.CurrentUserName == username
+
+	err := c.update(func(f *file) error {
+		p := f.profile(c.Profile)
+		if p.CurrentUserName == username {
+			p.CurrentUserName = ""
+			p.SessionToken = ""
+			f.setProfile(c.Profile, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if forgotten {
+		c.CurrentUserName = ""
+		c.SessionToken = ""
+	}
+
+	return nil
+}
+
+// UserFromEnv reports whether the current user was picked by GATOR_USER
+// rather than by logging in.
+func (c Config) UserFromEnv() bool {
+	return c.userFromEnv
+}
+
+// Detach keeps later changes to c in memory instead of writing them back to
+// the config file.
+func (c *Config) Detach() {
+	c.detached = true
+}
+
+// Path returns the file c was read from.
+func (c Config) Path() string {
+	return c.path
+}
+
+// Select switches to the named profile for as long as gator runs, without
+// changing which profile is active in the config file.
+func (c *Config) Select(name string) error {
+	if !c.file.hasProfile(name) {
+		return fmt.Errorf("no profile named '%s'", name)
+	}
+
+	c.Profile = name
+	c.resolve()
+
+	return nil
+}
+
+// UseProfile makes the named profile the active one, now and for later runs.
+func (c *Config) UseProfile(name string) error {
+	err := c.update(func(f *file) error {
+		if !f.hasProfile(name) {
+			return fmt.Errorf("no profile named '%s'", name)
+		}
+
+		f.Profile = name
+		if name == DefaultProfile {
+			f.Profile = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Select(name)
+}
+
+// ActiveProfile returns the profile the config file says to use.
+func (c Config) ActiveProfile() string {
+	if c.file.Profile == "" {
+		return DefaultProfile
+	}
+
+	return c.file.Profile
+}
+
+func (c Config) HasProfile(name string) bool {
+	return c.file.hasProfile(name)
+}
+
+// Profiles lists every profile by name, starting with the default one.
+func (c Config) Profiles() []string {
+	names := make([]string, 0, len(c.file.Profiles))
+	for name := range c.file.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return append([]string{DefaultProfile}, names...)
+}
+
+// GetProfile returns the settings stored for the named profile.
+func (c Config) GetProfile(name string) Profile {
+	return c.file.profile(name)
+}
+
+func (c *Config) AddProfile(name, dbURL string) error {
+	if name == "" {
+		return fmt.Errorf("profiles need a name")
+	}
+
+	return c.update(func(f *file) error {
+		if f.hasProfile(name) {
+			return fmt.Errorf("there's already a profile named '%s'", name)
+		}
+
+		// the same check 'gator config set db_url' makes
+		if dbURL != "" {
+			k, err := LookupKey("db_url")
+			if err != nil {
+				return err
+			}
+			err = validate(*f, k, dbURL)
+			if err != nil {
+				return err
+			}
+		}
+
+		f.setProfile(name, Profile{DBURL: dbURL})
+		return nil
+	})
+}
+
+// RemoveProfile deletes the named profile. If it was the active one, the
+// default profile takes over.
+func (c *Config) RemoveProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("the default profile can't be removed")
+	}
 
-	err := write(c)
+	err := c.update(func(f *file) error {
+		if !f.hasProfile(name) {
+			return fmt.Errorf("no profile named '%s'", name)
+		}
+
+		delete(f.Profiles, name)
+		if f.Profile == name {
+			f.Profile = ""
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
+	if c.Profile == name {
+		c.Profile = DefaultProfile
+		c.resolve()
+	}
+
 	return nil
 }
 
-const gatorConfig string = ".gatorconfig.json"
+// Aliases returns the user's shortcuts for commands, by name.
+func (c Config) Aliases() map[string]string {
+	return maps.Clone(c.file.Aliases)
+}
+
+// SetAlias makes name stand for expansion, replacing any alias already
+// called that.
+func (c *Config) SetAlias(name, expansion string) error {
+	return c.update(func(f *file) error {
+		if f.Aliases == nil {
+			f.Aliases = make(map[string]string)
+		}
+		f.Aliases[name] = expansion
+		return nil
+	})
+}
+
+func (c *Config) RemoveAlias(name string) error {
+	return c.update(func(f *file) error {
+		if _, ok := f.Aliases[name]; !ok {
+			return fmt.Errorf("no alias named '%s'", name)
+		}
+
+		delete(f.Aliases, name)
+		return nil
+	})
+}
+
+func (f file) hasProfile(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+
+	_, ok := f.Profiles[name]
+	return ok
+}
+
+func (f file) profile(name string) Profile {
+	if name == DefaultProfile || name == "" {
+		return Profile{DBURL: f.DBURL, CurrentUserName: f.CurrentUserName, SessionToken: f.SessionToken}
+	}
+
+	return f.Profiles[name]
+}
+
+func (f *file) setProfile(name string, p Profile) {
+	if name == DefaultProfile || name == "" {
+		f.DBURL = p.DBURL
+		f.CurrentUserName = p.CurrentUserName
+		f.SessionToken = p.SessionToken
+		return
+	}
+
+	if f.Profiles == nil {
+		f.Profiles = make(map[string]Profile)
+	}
+	f.Profiles[name] = p
+}
+
+const (
+	legacyConfig = ".gatorconfig.json"
+	appDir       = "gator"
+	configFile   = "config.json"
+)
+
+// DefaultPath returns $XDG_CONFIG_HOME/gator/config.json (with
+// XDG_CONFIG_HOME defaulting to ~/.config), or ~/.gatorconfig.json if that's
+// the only one that exists.
+func DefaultPath() (string, error) {
+	configHome, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(configHome, appDir, configFile)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(home, legacyConfig)
+
+	if !exists(path) && exists(legacy) {
+		return legacy, nil
+	}
+
+	return path, nil
+}
+
+// Read loads the config file at path, or at DefaultPath if path is empty,
+// then applies any environment overrides. A missing file gives an error
+// satisfying errors.Is(err, fs.ErrNotExist); see Create.
+func Read(path string) (Config, error) {
+	if path == "" {
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return Config{}, err
+		}
+	}
 
-func Read() (Config, error) {
-	path, err := getConfigFilePath()
+	f, err := readFile(path)
 	if err != nil {
 		return Config{}, err
 	}
 
-	data, err := os.ReadFile(path)
+	err = fixPermissions(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg := Config{path: path, file: f}
+	cfg.Profile = cfg.ActiveProfile()
+	if !cfg.HasProfile(cfg.Profile) {
+		return Config{}, fmt.Errorf("config file %s uses profile '%s', which doesn't exist", path, cfg.Profile)
+	}
+	cfg.resolve()
+
+	return cfg, nil
+}
+
+// Create writes a new config file at path (or DefaultPath) that points at
+// an SQLite database under $XDG_DATA_HOME/gator, for a first run where no
+// config exists yet.
+func Create(path string) (Config, error) {
+	cfg, err := Default(path)
 	if err != nil {
 		return Config{}, err
 	}
 
-	var cfg Config
-	err = json.Unmarshal(data, &cfg)
+	err = cfg.update(func(f *file) error { return nil })
 	if err != nil {
 		return Config{}, err
 	}
@@ -44,30 +404,198 @@ func Read() (Config, error) {
 	return cfg, nil
 }
 
-func getConfigFilePath() (string, error) {
+// Default returns the config Create would write, without writing it.
+func Default(path string) (Config, error) {
+	if path == "" {
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	dataHome, err := xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg := Config{
+		Profile: DefaultProfile,
+		path:    path,
+		file: file{
+			DBURL: "sqlite://" + filepath.Join(dataHome, appDir, "gator.db"),
+		},
+	}
+	cfg.resolve()
+
+	return cfg, nil
+}
+
+// resolve works out the settings to run with from the selected profile,
+// letting GATOR_DB_URL (or DATABASE_URL) and GATOR_USER take the place of
+// what's in the file.
+func (c *Config) resolve() {
+	p := c.file.profile(c.Profile)
+	c.DBURL = p.DBURL
+	c.CurrentUserName = p.CurrentUserName
+	c.SessionToken = p.SessionToken
+	c.SMTP = c.file.SMTP
+
+	dbURL := os.Getenv("GATOR_DB_URL")
+	if dbURL == "" {
+		dbURL = os.Getenv("DATABASE_URL")
+	}
+	if dbURL != "" {
+		c.DBURL = dbURL
+	}
+
+	// the session belongs to whoever logged in, so a user from GATOR_USER
+	// has to give their password instead
+	user := os.Getenv("GATOR_USER")
+	c.userFromEnv = user != ""
+	if c.userFromEnv {
+		c.CurrentUserName = user
+		c.SessionToken = ""
+	}
+}
+
+// update applies change to the config file. The file is locked and read
+// again first, so changes made by another gator process in the meantime
+// aren't lost.
+func (c *Config) update(change func(f *file) error) error {
+	if c.detached {
+		err := change(&c.file)
+		if err != nil {
+			return err
+		}
+		c.resolve()
+		return nil
+	}
+
+	if c.path == "" {
+		return fmt.Errorf("config has no file to be written to")
+	}
+
+	err := os.MkdirAll(filepath.Dir(c.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lock(c.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking config file: %w", err)
+	}
+	defer unlock()
+
+	f, err := readFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		f = c.file
+	} else if err != nil {
+		return err
+	}
+
+	err = change(&f)
+	if err != nil {
+		return err
+	}
+
+	err = write(c.path, f)
+	if err != nil {
+		return err
+	}
+
+	c.file = f
+	c.resolve()
+
+	return nil
+}
+
+func xdgDir(env, fallback string) (string, error) {
+	dir := os.Getenv(env)
+	if filepath.IsAbs(dir) {
+		return dir, nil
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%s/%s", home, gatorConfig), nil
+	return filepath.Join(home, fallback), nil
 }
 
-func write(cfg Config) error {
-	path, err := getConfigFilePath()
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func readFile(path string) (file, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file{}, err
+	}
+
+	var f file
+	err = json.Unmarshal(data, &f)
+	if err != nil {
+		return file{}, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// write replaces the config file in one step, by writing a temporary file
+// next to it and renaming that over the top, so a crash or a concurrent
+// reader never sees half a config, and syncing the directory after so the
+// rename survives a crash too. The file holds passwords, so only its owner
+// may read it.
+func write(path string, f file) error {
+	data, err := json.MarshalIndent(f, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	// does nothing once the rename has happened
+	defer os.Remove(tmp.Name())
 
-	err = os.WriteFile(path, data, 0777)
+	err = tmp.Chmod(0600)
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
 	if err != nil {
 		return err
 	}
+	if closeErr != nil {
+		return closeErr
+	}
 
-	return nil
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// fixPermissions takes away access other users have to a config file
+// written by an older version of gator.
+func fixPermissions(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().Perm()&0077 == 0 {
+		return nil
+	}
+
+	return os.Chmod(path, 0600)
 }
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// testHome points HOME and XDG_CONFIG_HOME at an empty directory, and clears
+// the environment overrides.
+func testHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "GATOR_DB_URL", "DATABASE_URL", "GATOR_USER"} {
+		t.Setenv(env, "")
+	}
+
+	return home
+}
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(data), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	home := testHome(t)
+	xdg := filepath.Join(home, ".config", "gator", "config.json")
+	legacy := filepath.Join(home, ".gatorconfig.json")
+
+	check := func(want string) {
+		t.Helper()
+
+		got, err := DefaultPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	// a first run gets the new location
+	check(xdg)
+
+	// an old config is used while it's the only one
+	writeConfig(t, legacy, `{}`)
+	check(legacy)
+
+	writeConfig(t, xdg, `{}`)
+	check(xdg)
+
+	// XDG_CONFIG_HOME moves the new location, as long as it's absolute
+	elsewhere := filepath.Join(home, "elsewhere")
+	t.Setenv("XDG_CONFIG_HOME", elsewhere)
+	check(legacy)
+
+	t.Setenv("XDG_CONFIG_HOME", "relative")
+	check(xdg)
+}
+
+func TestEnvironmentOverrides(t *testing.T) {
+	home := testHome(t)
+	path := filepath.Join(home, "config.json")
+	writeConfig(t, path, `{"db_url": "sqlite://file.db", "current_user_name": "alice", "session_token": "token"}`)
+
+	for _, tc := range []struct {
+		name    string
+		env     map[string]string
+		dbURL   string
+		user    string
+		token   string
+		fromEnv bool
+	}{
+		{"none", nil, "sqlite://file.db", "alice", "token", false},
+		{"DATABASE_URL", map[string]string{"DATABASE_URL": "postgres://database"}, "postgres://database", "alice", "token", false},
+		{
+			"GATOR_DB_URL before DATABASE_URL",
+			map[string]string{"DATABASE_URL": "postgres://database", "GATOR_DB_URL": "postgres://gator"},
+			"postgres://gator", "alice", "token", false,
+		},
+		// alice's session isn't bob's
+		{"GATOR_USER", map[string]string{"GATOR_USER": "bob"}, "sqlite://file.db", "bob", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for env, value := range tc.env {
+				t.Setenv(env, value)
+			}
+
+			cfg, err := Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.DBURL != tc.dbURL || cfg.CurrentUserName != tc.user || cfg.SessionToken != tc.token || cfg.UserFromEnv() != tc.fromEnv {
+				t.Errorf("got db_url %q, user %q, token %q, from env %v", cfg.DBURL, cfg.CurrentUserName, cfg.SessionToken, cfg.UserFromEnv())
+			}
+
+			// the file itself keeps its own settings
+			stored, err := cfg.Get("db_url")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored != "sqlite://file.db" {
+				t.Errorf("got stored db_url %q", stored)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	home := testHome(t)
+	path := filepath.Join(home, "config.json")
+	writeConfig(t, path, `{"db_url": "sqlite://own.db", "current_user_name": "alice"}`)
+
+	cfg, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cfg.AddProfile("team", "postgres://team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, dbURL := range map[string]string{"team": "postgres://other", "": "postgres://other", "bad": "not-a-url"} {
+		if err := cfg.AddProfile(name, dbURL); err == nil {
+			t.Errorf("added profile %q with %q", name, dbURL)
+		}
+	}
+
+	err = cfg.UseProfile("team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "team" || cfg.DBURL != "postgres://team" || cfg.CurrentUserName != "" {
+		t.Errorf("using profile %q with db_url %q and user %q", cfg.Profile, cfg.DBURL, cfg.CurrentUserName)
+	}
+	if err := cfg.UseProfile("missing"); err == nil {
+		t.Error("used a profile that doesn't exist")
+	}
+
+	// later runs use it too
+	reread, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reread.Profile != "team" || reread.DBURL != "postgres://team" {
+		t.Errorf("next run uses profile %q with db_url %q", reread.Profile, reread.DBURL)
+	}
+
+	if err := cfg.RemoveProfile(DefaultProfile); err == nil {
+		t.Error("removed the default profile")
+	}
+
+	// removing the active profile goes back to the default one
+	err = cfg.RemoveProfile("team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != DefaultProfile || cfg.DBURL != "sqlite://own.db" || cfg.CurrentUserName != "alice" {
+		t.Errorf("using profile %q with db_url %q and user %q", cfg.Profile, cfg.DBURL, cfg.CurrentUserName)
+	}
+	if err := cfg.RemoveProfile("team"); err == nil {
+		t.Error("removed a profile twice")
+	}
+
+	reread, err = Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reread.ActiveProfile() != DefaultProfile || len(reread.Profiles()) != 1 {
+		t.Errorf("next run has profiles %q, with %q active", reread.Profiles(), reread.ActiveProfile())
+	}
+}
+
+func TestConcurrentWritersKeepEachOthersChanges(t *testing.T) {
+	home := testHome(t)
+	path := filepath.Join(home, "gator", "config.json")
+	writeConfig(t, path, `{"db_url": "sqlite://own.db"}`)
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// each writer read the file before any of the others saved
+			cfg, err := Read(path)
+			if err == nil {
+				err = cfg.SetAlias(fmt.Sprintf("a%d", i), "browse")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(cfg.Aliases()); n != writers {
+		t.Errorf("got %d aliases, want %d", n, writers)
+	}
+
+	// nothing but the config is left over
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %v next to the config", entries)
+	}
+}
+
+func TestConfigIsPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows doesn't have unix permissions")
+	}
+
+	home := testHome(t)
+	path := filepath.Join(home, "config.json")
+
+	mode := func() os.FileMode {
+		t.Helper()
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	cfg, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := mode(); m != 0600 {
+		t.Errorf("new config has mode %v", m)
+	}
+
+	// older versions wrote the file for everyone to read
+	err = os.Chmod(path, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := mode(); m != 0600 {
+		t.Errorf("read config has mode %v", m)
+	}
+
+	err = cfg.SetAlias("b", "browse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := mode(); m != 0600 {
+		t.Errorf("written config has mode %v", m)
+	}
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// dbSchemes are the kinds of url database.Open understands.
+var dbSchemes = []string{"postgres", "postgresql", "sqlite", "sqlite3", "file"}
+
+// Key describes a setting that can be read and changed with the config
+// command.
+type Key struct {
+	Name        string
+	Description string
+	// Type is one of "string", "int", "url" or "profile"
+	Type string
+	// Secret values are hidden when listing settings
+	Secret bool
+	// Env names the environment variables that override this setting
+	Env []string
+	// ReadOnlyHint, when set, makes the setting read-only and says which
+	// command changes it instead. It can still be unset.
+	ReadOnlyHint string
+
+	get func(f *file, profile string) string
+	set func(f *file, profile string, value string)
+}
+
+// Keys is every setting gator knows about. db_url and current_user_name
+// belong to the profile in use.
+var Keys = []Key{
+	{
+		Name:        "db_url",
+		Description: "database to use, a postgres:// or sqlite:// url",
+		Type:        "url",
+		Env:         []string{"GATOR_DB_URL", "DATABASE_URL"},
+		get:         func(f *file, profile string) string { return f.profile(profile).DBURL },
+		set: func(f *file, profile string, value string) {
+			p := f.profile(profile)
+			p.DBURL = value
+			f.setProfile(profile, p)
+		},
+	},
+	{
+		Name:         "current_user_name",
+		Description:  "user that's logged in",
+		Type:         "string",
+		Env:          []string{"GATOR_USER"},
+		ReadOnlyHint: "use 'gator login' to change the current user",
+		get:          func(f *file, profile string) string { return f.profile(profile).CurrentUserName },
+		set: func(f *file, profile string, value string) {
+			p := f.profile(profile)
+			p.CurrentUserName = value
+			p.SessionToken = ""
+			f.setProfile(profile, p)
+		},
+	},
+	{
+		Name:        "profile",
+		Description: "profile to use when --profile isn't given",
+		Type:        "profile",
+		get: func(f *file, profile string) string {
+			if f.Profile == "" {
+				return DefaultProfile
+			}
+			return f.Profile
+		},
+		set: func(f *file, profile string, value string) {
+			if value == DefaultProfile {
+				value = ""
+			}
+			f.Profile = value
+		},
+	},
+	smtpKey("smtp.host", "SMTP server to send digests through", "string", false,
+		func(s *SMTP) *string { return &s.Host }),
+	{
+		Name:        "smtp.port",
+		Description: "port of the SMTP server",
+		Type:        "int",
+		get: func(f *file, profile string) string {
+			if f.SMTP.Port == 0 {
+				return ""
+			}
+			return strconv.Itoa(f.SMTP.Port)
+		},
+		set: func(f *file, profile string, value string) {
+			f.SMTP.Port, _ = strconv.Atoi(value)
+		},
+	},
+	smtpKey("smtp.username", "user name for the SMTP server", "string", false,
+		func(s *SMTP) *string { return &s.Username }),
+	smtpKey("smtp.password", "password for the SMTP server", "string", true,
+		func(s *SMTP) *string { return &s.Password }),
+	smtpKey("smtp.from", "address digests are sent from", "string", false,
+		func(s *SMTP) *string { return &s.From }),
+	smtpKey("smtp.to", "address digests are sent to by default", "string", false,
+		func(s *SMTP) *string { return &s.To }),
+}
+
+func smtpKey(name, description, typ string, secret bool, field func(*SMTP) *string) Key {
+	return Key{
+		Name:        name,
+		Description: description,
+		Type:        typ,
+		Secret:      secret,
+		get:         func(f *file, profile string) string { return *field(&f.SMTP) },
+		set:         func(f *file, profile string, value string) { *field(&f.SMTP) = value },
+	}
+}
+
+// Display returns value the way it's safe to show: hidden if it's secret,
+// and without the password if it's a url.
+func (k Key) Display(value string) string {
+	if value == "" {
+		return ""
+	}
+	if k.Secret {
+		return "********"
+	}
+	if k.Type == "url" {
+		return RedactURL(value)
+	}
+
+	return value
+}
+
+// RedactURL hides the password in a database url, like url.URL.Redacted.
+func RedactURL(dbURL string) string {
+	u, err := url.Parse(dbURL)
+	if err != nil || u.User == nil {
+		return dbURL
+	}
+
+	return u.Redacted()
+}
+
+func LookupKey(name string) (Key, error) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("unknown setting '%s'", name)
+}
+
+// Get returns the value of the named setting as it's stored in the config
+// file, ignoring environment overrides.
+func (c Config) Get(name string) (string, error) {
+	k, err := LookupKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	return k.get(&c.file, c.Profile), nil
+}
+
+// Set checks value against the named setting's type and saves it.
+func (c *Config) Set(name, value string) error {
+	k, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	if k.ReadOnlyHint != "" {
+		return fmt.Errorf("%s can't be set directly, %s", k.Name, k.ReadOnlyHint)
+	}
+
+	return c.change(k, value)
+}
+
+// Unset clears the named setting.
+func (c *Config) Unset(name string) error {
+	k, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+
+	return c.change(k, "")
+}
+
+func (c *Config) change(k Key, value string) error {
+	// changing the active profile also changes the one in use, unless
+	// --profile picked a different one
+	followActive := c.Profile == c.ActiveProfile()
+
+	err := c.update(func(f *file) error {
+		if value != "" {
+			err := validate(*f, k, value)
+			if err != nil {
+				return err
+			}
+		}
+
+		k.set(f, c.Profile, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if followActive {
+		c.Profile = c.ActiveProfile()
+		c.resolve()
+	}
+
+	return nil
+}
+
+// OverriddenBy returns the environment variable currently overriding the
+// named setting, if any.
+func (c Config) OverriddenBy(name string) string {
+	k, err := LookupKey(name)
+	if err != nil {
+		return ""
+	}
+
+	for _, env := range k.Env {
+		if os.Getenv(env) != "" {
+			return env
+		}
+	}
+
+	return ""
+}
+
+func validate(f file, k Key, value string) error {
+	switch k.Type {
+	case "int":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s must be a whole number, not '%s'", k.Name, value)
+		}
+		if k.Name == "smtp.port" && n > 65535 {
+			return fmt.Errorf("%s must be at most 65535", k.Name)
+		}
+	case "url":
+		u, err := url.Parse(value)
+		if err != nil || !slices.Contains(dbSchemes, u.Scheme) {
+			return fmt.Errorf("%s must be a url like postgres://... or sqlite://..., not '%s'", k.Name, value)
+		}
+	case "profile":
+		if !f.hasProfile(value) {
+			return fmt.Errorf("no profile named '%s'", value)
+		}
+	}
+
+	return nil
+}
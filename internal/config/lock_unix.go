@@ -0,0 +1,67 @@
+//go:build unix
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive lock on the file at path, creating it if needed,
+// and waits for any other gator process holding it to let go. Unlocking
+// removes the file again.
+func lock(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return nil, err
+		}
+
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		// whoever had the lock before may have removed the file while we
+		// waited, and anyone arriving since has locked a new one instead
+		if sameFile(f, path) {
+			return func() {
+				os.Remove(path)
+				syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+				f.Close()
+			}, nil
+		}
+
+		f.Close()
+	}
+}
+
+func sameFile(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(opened, current)
+}
+
+// syncDir makes sure a rename in dir has reached the disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = d.Sync()
+	closeErr := d.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
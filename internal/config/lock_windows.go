@@ -0,0 +1,40 @@
+//go:build windows
+
+package config
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lock takes an exclusive lock on the file at path, creating it if needed,
+// and waits for any other gator process holding it to let go. Unlocking
+// removes the file again, unless another process already has it open.
+func lock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	handle := windows.Handle(f.Fd())
+	overlapped := new(windows.Overlapped)
+	err = windows.LockFileEx(handle, windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		windows.UnlockFileEx(handle, 0, 1, 0, overlapped)
+		f.Close()
+		// Windows won't remove a file someone else has open, so this can't
+		// pull the lock out from under a process waiting for it
+		os.Remove(path)
+	}, nil
+}
+
+// syncDir does nothing, since Windows doesn't support syncing a directory.
+func syncDir(dir string) error {
+	return nil
+}
@@ -12,28 +12,41 @@ import (
 	"github.com/google/uuid"
 )
 
+const countFeedFollows = `-- name: CountFeedFollows :one
+SELECT COUNT(*) FROM feed_follows
+`
+
+func (q *Queries) CountFeedFollows(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollows)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFeedFollowsForUser = `-- name: CountFeedFollowsForUser :one
+SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = $1
+`
+
+func (q *Queries) CountFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollowsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :one
-WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-    VALUES (
-        $1,
-        $2,
-        $3,
-        $4,
-        $5
-    )
-
-    RETURNING id, created_at, updated_at, user_id, feed_id
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
 )
-SELECT
-    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
-    feeds.name AS feed_name,
-    users.name AS user_name
-FROM inserted_feed_follow
-INNER JOIN feeds
-ON inserted_feed_follow.feed_id = feeds.id
-INNER JOIN users
-ON inserted_feed_follow.user_id = users.id
+RETURNING
+    id, created_at, updated_at, user_id, feed_id,
+    (SELECT feeds.name FROM feeds WHERE feeds.id = feed_follows.feed_id) AS feed_name,
+    (SELECT users.name FROM users WHERE users.id = feed_follows.user_id) AS user_name
 `
 
 type CreateFeedFollowParams struct {
@@ -130,12 +143,11 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, name string) ([]Get
 }
 
 const unfollowUserFromFeed = `-- name: UnfollowUserFromFeed :one
-WITH feed_id AS (
+DELETE FROM feed_follows
+WHERE feed_follows.feed_id = (
     SELECT feeds.id FROM feeds
     WHERE feeds.url = $1
-)
-DELETE FROM feed_follows
-WHERE feed_follows.feed_id = feed_id AND feed_follows.user_id = $2
+) AND feed_follows.user_id = $2
 RETURNING feed_follows.feed_id
 `
 
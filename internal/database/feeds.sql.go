@@ -13,6 +13,61 @@ import (
 	"github.com/google/uuid"
 )
 
+const clearFeedLegacyGUIDs = `-- name: ClearFeedLegacyGUIDs :exec
+UPDATE feeds
+SET legacy_guids = FALSE
+WHERE feeds.id = $1
+`
+
+func (q *Queries) ClearFeedLegacyGUIDs(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearFeedLegacyGUIDs, id)
+	return err
+}
+
+const countFeedFollowers = `-- name: CountFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1
+`
+
+func (q *Queries) CountFeedFollowers(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollowers, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFeeds = `-- name: CountFeeds :one
+SELECT COUNT(*) FROM feeds
+`
+
+func (q *Queries) CountFeeds(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeeds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFeedsForUser = `-- name: CountFeedsForUser :one
+SELECT COUNT(*) FROM feeds WHERE feeds.user_id = $1
+`
+
+func (q *Queries) CountFeedsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPostsForFeed = `-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts WHERE posts.feed_id = $1
+`
+
+func (q *Queries) CountPostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -23,7 +78,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, title, site_url, description, image_url, language, legacy_guids
 `
 
 type CreateFeedParams struct {
@@ -53,12 +108,36 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.Language,
+		&i.LegacyGuids,
 	)
 	return i, err
 }
 
+const deleteAllFeeds = `-- name: DeleteAllFeeds :exec
+DELETE FROM feeds
+`
+
+func (q *Queries) DeleteAllFeeds(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllFeeds)
+	return err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE feeds.id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE feeds.id = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, title, site_url, description, image_url, language, legacy_guids FROM feeds WHERE feeds.id = $1
 `
 
 func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -72,12 +151,18 @@ func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.Language,
+		&i.LegacyGuids,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE feeds.url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, title, site_url, description, image_url, language, legacy_guids FROM feeds WHERE feeds.url = $1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -91,12 +176,18 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.Language,
+		&i.LegacyGuids,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, title, site_url, description, image_url, language, legacy_guids FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -116,6 +207,12 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Title,
+			&i.SiteUrl,
+			&i.Description,
+			&i.ImageUrl,
+			&i.Language,
+			&i.LegacyGuids,
 		); err != nil {
 			return nil, err
 		}
@@ -131,7 +228,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, title, site_url, description, image_url, language, legacy_guids FROM feeds
 ORDER BY feeds.last_fetched_at ASC NULLS FIRST
 `
 
@@ -146,6 +243,12 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.Language,
+		&i.LegacyGuids,
 	)
 	return i, err
 }
@@ -165,3 +268,87 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.ID)
 	return err
 }
+
+const transferFeeds = `-- name: TransferFeeds :exec
+UPDATE feeds
+SET user_id = $1, updated_at = $2
+WHERE feeds.user_id = $3
+`
+
+type TransferFeedsParams struct {
+	UserID    uuid.UUID
+	UpdatedAt time.Time
+	UserID_2  uuid.UUID
+}
+
+func (q *Queries) TransferFeeds(ctx context.Context, arg TransferFeedsParams) error {
+	_, err := q.db.ExecContext(ctx, transferFeeds, arg.UserID, arg.UpdatedAt, arg.UserID_2)
+	return err
+}
+
+const updateFeed = `-- name: UpdateFeed :one
+UPDATE feeds
+SET name = $1, url = $2, updated_at = $3
+WHERE feeds.id = $4
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, title, site_url, description, image_url, language, legacy_guids
+`
+
+type UpdateFeedParams struct {
+	Name      string
+	Url       string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeed,
+		arg.Name,
+		arg.Url,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.ImageUrl,
+		&i.Language,
+		&i.LegacyGuids,
+	)
+	return i, err
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET title = $1, site_url = $2, description = $3, image_url = $4, language = $5
+WHERE feeds.id = $6
+`
+
+type UpdateFeedMetadataParams struct {
+	Title       sql.NullString
+	SiteUrl     sql.NullString
+	Description sql.NullString
+	ImageUrl    sql.NullString
+	Language    sql.NullString
+	ID          uuid.UUID
+}
+
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.Title,
+		arg.SiteUrl,
+		arg.Description,
+		arg.ImageUrl,
+		arg.Language,
+		arg.ID,
+	)
+	return err
+}
@@ -11,6 +11,15 @@ import (
 	"github.com/google/uuid"
 )
 
+type Download struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	EnclosureID uuid.UUID
+	Path        string
+	Size        int64
+	Sha256      string
+}
+
 type Feed struct {
 	ID            uuid.UUID
 	CreatedAt     time.Time
@@ -19,6 +28,12 @@ type Feed struct {
 	Url           string
 	UserID        uuid.UUID
 	LastFetchedAt sql.NullTime
+	Title         sql.NullString
+	SiteUrl       sql.NullString
+	Description   sql.NullString
+	ImageUrl      sql.NullString
+	Language      sql.NullString
+	LegacyGuids   bool
 }
 
 type FeedFollow struct {
@@ -29,20 +44,94 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type Filter struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Action    string
+	Field     string
+	Pattern   string
+}
+
 type Post struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Title       sql.NullString
-	Url         string
+	Url         sql.NullString
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	Guid        string
+	Content     sql.NullString
+	Author      sql.NullString
+	CommentsUrl sql.NullString
+}
+
+type PostCategory struct {
+	PostID uuid.UUID
+	Name   string
+}
+
+type PostEnclosure struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	PostID       uuid.UUID
+	Url          string
+	Source       string
+	MediaType    sql.NullString
+	Length       sql.NullInt64
+	Duration     sql.NullString
+	Episode      sql.NullString
+	ImageUrl     sql.NullString
+	ThumbnailUrl sql.NullString
+}
+
+type PostRevision struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	PostID      uuid.UUID
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt sql.NullTime
+}
+
+type Session struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
 }
 
 type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	PasswordHash sql.NullString
+}
+
+type Webhook struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Name      string
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Url       string
+	Keyword   sql.NullString
+}
+
+type WebhookDelivery struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	WebhookID  uuid.UUID
+	PostID     uuid.UUID
+	Attempt    int32
+	StatusCode sql.NullInt32
+	Error      sql.NullString
+	Succeeded  bool
 }
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite3"
+)
+
+// Dialect works out which kind of database dbURL points at from its scheme.
+// Anything that isn't an sqlite: or file: url is taken to be PostgreSQL.
+func Dialect(dbURL string) string {
+	if strings.HasPrefix(dbURL, "sqlite:") || strings.HasPrefix(dbURL, "sqlite3:") || strings.HasPrefix(dbURL, "file:") {
+		return DialectSQLite
+	}
+
+	return DialectPostgres
+}
+
+// Open connects to the database at dbURL and returns queries that speak its
+// dialect.
+func Open(dbURL string) (*sql.DB, *Queries, error) {
+	if Dialect(dbURL) == DialectPostgres {
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return db, New(db), nil
+	}
+
+	dsn, err := SQLitePath(dbURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// SQLite creates the database file itself, but not the directory for it
+	err = os.MkdirAll(filepath.Dir(dsn), 0755)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dsn+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, nil, err
+	}
+	// SQLite only allows one writer at a time anyway, and a single connection
+	// keeps goroutines from tripping over each other's locks
+	db.SetMaxOpenConns(1)
+
+	return db, New(sqliteDB{db: db}), nil
+}
+
+// SQLitePath returns the file an SQLite db_url refers to. sqlite:///abs/path.db,
+// sqlite://relative.db, sqlite:relative.db and file:path.db are all accepted,
+// and a leading ~/ is expanded to the home directory.
+func SQLitePath(dbURL string) (string, error) {
+	path := dbURL
+	for _, scheme := range []string{"sqlite3:", "sqlite:", "file:"} {
+		if strings.HasPrefix(path, scheme) {
+			path = strings.TrimPrefix(path, scheme)
+			break
+		}
+	}
+	path = strings.TrimPrefix(path, "//")
+
+	path, err := url.PathUnescape(path)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	return path, nil
+}
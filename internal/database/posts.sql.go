@@ -13,8 +13,44 @@ import (
 	"github.com/google/uuid"
 )
 
+const countPostRevisions = `-- name: CountPostRevisions :one
+SELECT COUNT(*) FROM post_revisions
+`
+
+func (q *Queries) CountPostRevisions(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostRevisions)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPosts = `-- name: CountPosts :one
+SELECT COUNT(*) FROM posts
+`
+
+func (q *Queries) CountPosts(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPosts)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPostsForUser = `-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+`
+
+func (q *Queries) CountPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content, author, comments_url)
 VALUES (
     $1,
     $2,
@@ -23,9 +59,14 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10,
+    $11,
+    $12
 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+ON CONFLICT (feed_id, guid) DO NOTHING
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content, author, comments_url
 `
 
 type CreatePostParams struct {
@@ -33,10 +74,14 @@ type CreatePostParams struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Title       sql.NullString
-	Url         string
+	Url         sql.NullString
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	Guid        string
+	Content     sql.NullString
+	Author      sql.NullString
+	CommentsUrl sql.NullString
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -49,6 +94,10 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Guid,
+		arg.Content,
+		arg.Author,
+		arg.CommentsUrl,
 	)
 	var i Post
 	err := row.Scan(
@@ -60,24 +109,214 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Guid,
+		&i.Content,
+		&i.Author,
+		&i.CommentsUrl,
 	)
 	return i, err
 }
 
-const getPostsForUser = `-- name: GetPostsForUser :many
-WITH followed_feeds AS (
-    SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follows
+const createPostCategory = `-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT DO NOTHING
+`
+
+type CreatePostCategoryParams struct {
+	PostID uuid.UUID
+	Name   string
+}
+
+func (q *Queries) CreatePostCategory(ctx context.Context, arg CreatePostCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, createPostCategory, arg.PostID, arg.Name)
+	return err
+}
+
+const createPostRevision = `-- name: CreatePostRevision :exec
+INSERT INTO post_revisions (id, created_at, post_id, title, url, description, published_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+`
+
+type CreatePostRevisionParams struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	PostID      uuid.UUID
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) CreatePostRevision(ctx context.Context, arg CreatePostRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createPostRevision,
+		arg.ID,
+		arg.CreatedAt,
+		arg.PostID,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+	)
+	return err
+}
+
+const deleteAllPosts = `-- name: DeleteAllPosts :exec
+DELETE FROM posts
+`
+
+func (q *Queries) DeleteAllPosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllPosts)
+	return err
+}
+
+const deletePostCategories = `-- name: DeletePostCategories :exec
+DELETE FROM post_categories
+WHERE post_categories.post_id = $1
+`
+
+func (q *Queries) DeletePostCategories(ctx context.Context, postID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deletePostCategories, postID)
+	return err
+}
+
+const getCategoriesForPost = `-- name: GetCategoriesForPost :many
+SELECT post_categories.name FROM post_categories
+WHERE post_categories.post_id = $1
+ORDER BY post_categories.name ASC
+`
+
+func (q *Queries) GetCategoriesForPost(ctx context.Context, postID uuid.UUID) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getCategoriesForPost, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCategoriesForPostsPage = `-- name: GetCategoriesForPostsPage :many
+SELECT post_categories.post_id, post_categories.name FROM post_categories
+WHERE post_categories.post_id IN (
+    SELECT posts.id FROM posts
+    INNER JOIN feed_follows
+    ON posts.feed_id = feed_follows.feed_id
     WHERE feed_follows.user_id = $1
+    ORDER BY posts.published_at DESC NULLS LAST, posts.id ASC
+    LIMIT $2
+    OFFSET $3
 )
-SELECT posts.id, posts.created_at, posts.updated_at, title, url, description, published_at, posts.feed_id, followed_feeds.id, followed_feeds.created_at, followed_feeds.updated_at, user_id, followed_feeds.feed_id FROM posts, followed_feeds
-WHERE posts.feed_id = followed_feeds.feed_id
-ORDER BY posts.published_at DESC
+ORDER BY post_categories.name ASC
+`
+
+type GetCategoriesForPostsPageParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+// the categories of the posts GetPostsForUser returns for the same page
+func (q *Queries) GetCategoriesForPostsPage(ctx context.Context, arg GetCategoriesForPostsPageParams) ([]PostCategory, error) {
+	rows, err := q.db.QueryContext(ctx, getCategoriesForPostsPage, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostCategory
+	for rows.Next() {
+		var i PostCategory
+		if err := rows.Scan(&i.PostID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostByGUID = `-- name: GetPostByGUID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content, author, comments_url FROM posts
+WHERE posts.feed_id = $1 AND posts.guid = $2
+`
+
+type GetPostByGUIDParams struct {
+	FeedID uuid.UUID
+	Guid   string
+}
+
+func (q *Queries) GetPostByGUID(ctx context.Context, arg GetPostByGUIDParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByGUID, arg.FeedID, arg.Guid)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.Content,
+		&i.Author,
+		&i.CommentsUrl,
+	)
+	return i, err
+}
+
+const getPostsForUser = `-- name: GetPostsForUser :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content, posts.author, posts.comments_url,
+    EXISTS (
+        SELECT 1 FROM post_revisions
+        WHERE post_revisions.post_id = posts.id
+    ) AS updated,
+    feeds.name AS feed_name
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY posts.published_at DESC NULLS LAST, posts.id ASC
 LIMIT $2
+OFFSET $3
 `
 
 type GetPostsForUserParams struct {
 	UserID uuid.UUID
 	Limit  int32
+	Offset int32
 }
 
 type GetPostsForUserRow struct {
@@ -85,19 +324,20 @@ type GetPostsForUserRow struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Title       sql.NullString
-	Url         string
+	Url         sql.NullString
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
-	ID_2        uuid.UUID
-	CreatedAt_2 time.Time
-	UpdatedAt_2 time.Time
-	UserID      uuid.UUID
-	FeedID_2    uuid.UUID
+	Guid        string
+	Content     sql.NullString
+	Author      sql.NullString
+	CommentsUrl sql.NullString
+	Updated     bool
+	FeedName    string
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -114,11 +354,12 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
-			&i.ID_2,
-			&i.CreatedAt_2,
-			&i.UpdatedAt_2,
-			&i.UserID,
-			&i.FeedID_2,
+			&i.Guid,
+			&i.Content,
+			&i.Author,
+			&i.CommentsUrl,
+			&i.Updated,
+			&i.FeedName,
 		); err != nil {
 			return nil, err
 		}
@@ -132,3 +373,142 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getPostsForUserSince = `-- name: GetPostsForUserSince :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content, posts.author, posts.comments_url,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = $1 AND posts.created_at >= $2
+ORDER BY feeds.name ASC, feeds.url ASC, posts.published_at DESC NULLS LAST
+`
+
+type GetPostsForUserSinceParams struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type GetPostsForUserSinceRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Guid        string
+	Content     sql.NullString
+	Author      sql.NullString
+	CommentsUrl sql.NullString
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserSince(ctx context.Context, arg GetPostsForUserSinceParams) ([]GetPostsForUserSinceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserSince, arg.UserID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserSinceRow
+	for rows.Next() {
+		var i GetPostsForUserSinceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Guid,
+			&i.Content,
+			&i.Author,
+			&i.CommentsUrl,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePost = `-- name: UpdatePost :one
+UPDATE posts
+SET updated_at = $1, title = $2, url = $3, description = $4, published_at = $5, content = $6, author = $7, comments_url = $8
+WHERE posts.id = $9
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content, author, comments_url
+`
+
+type UpdatePostParams struct {
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         sql.NullString
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	Content     sql.NullString
+	Author      sql.NullString
+	CommentsUrl sql.NullString
+	ID          uuid.UUID
+}
+
+func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, updatePost,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.Content,
+		arg.Author,
+		arg.CommentsUrl,
+		arg.ID,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.Content,
+		&i.Author,
+		&i.CommentsUrl,
+	)
+	return i, err
+}
+
+const updatePostGUID = `-- name: UpdatePostGUID :exec
+UPDATE posts
+SET guid = $1
+WHERE posts.id = $2
+`
+
+type UpdatePostGUIDParams struct {
+	Guid string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdatePostGUID(ctx context.Context, arg UpdatePostGUIDParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostGUID, arg.Guid, arg.ID)
+	return err
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// sqliteDB lets the generated queries, which use PostgreSQL's $N
+// placeholders, run against SQLite. SQLite reads $1 as a named parameter and
+// numbers those in order of appearance, so they're rewritten to ?1 instead
+// (leaving strings, quoted names and comments alone).
+// Times are stored as text, so they're converted to UTC to keep them
+// comparable.
+type sqliteDB struct {
+	db DBTX
+}
+
+func (s sqliteDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.db.ExecContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func (s sqliteDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return s.db.PrepareContext(ctx, sqliteQuery(query))
+}
+
+func (s sqliteDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func (s sqliteDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRowContext(ctx, sqliteQuery(query), sqliteArgs(args)...)
+}
+
+func sqliteQuery(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		// copy anything quoted or commented out as it is
+		end := ""
+		switch {
+		case c == '\'' || c == '"':
+			end = string(c)
+		case strings.HasPrefix(query[i:], "--"):
+			end = "\n"
+		case strings.HasPrefix(query[i:], "/*"):
+			end = "*/"
+		}
+		if end != "" {
+			j := strings.Index(query[i+1:], end)
+			if j == -1 {
+				b.WriteString(query[i:])
+				break
+			}
+			j += i + 1 + len(end)
+			b.WriteString(query[i:j])
+			i = j - 1
+			continue
+		}
+
+		if c == '$' && i+1 < len(query) && isDigit(query[i+1]) {
+			c = '?'
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func sqliteArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case sql.NullTime:
+			if v.Valid {
+				v.Time = v.Time.UTC()
+			}
+			converted[i] = v
+		default:
+			converted[i] = arg
+		}
+	}
+
+	return converted
+}
@@ -0,0 +1,25 @@
+package database
+
+import "testing"
+
+func TestSQLiteQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM users WHERE name = $1 LIMIT $2", "SELECT * FROM users WHERE name = ?1 LIMIT ?2"},
+		{"SELECT '$1', $1", "SELECT '$1', ?1"},
+		{"SELECT 'it''s $2', $2", "SELECT 'it''s $2', ?2"},
+		{`SELECT "$1" FROM t WHERE a = $1`, `SELECT "$1" FROM t WHERE a = ?1`},
+		{"SELECT $1 -- not $2\nFROM t", "SELECT ?1 -- not $2\nFROM t"},
+		{"SELECT /* $1 */ $1", "SELECT /* $1 */ ?1"},
+		{"SELECT '$1", "SELECT '$1"},
+		{"SELECT $ FROM t", "SELECT $ FROM t"},
+	}
+	for _, tt := range tests {
+		got := sqliteQuery(tt.query)
+		if got != tt.want {
+			t.Errorf("sqliteQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
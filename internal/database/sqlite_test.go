@@ -0,0 +1,483 @@
+package database_test
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/memory"
+	"github.com/45uperman/gator/internal/migrate"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openSQLite(t *testing.T) (*sql.DB, *database.Queries) {
+	t.Helper()
+
+	db, queries, err := database.Open("sqlite://" + filepath.Join(t.TempDir(), "gator.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = migrate.Up(db, database.DialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, queries
+}
+
+// result is what a query gave back, with errors boiled down to whether
+// there was one, since the stores word theirs differently.
+type result struct {
+	Value  any
+	NoRows bool
+	Failed bool
+}
+
+// recorder notes the result of every query run through it, by name.
+type recorder struct {
+	results map[string][]result
+}
+
+func (r *recorder) add(name string, value any, err error) {
+	res := result{Value: value}
+	if errors.Is(err, sql.ErrNoRows) {
+		res = result{NoRows: true}
+	} else if err != nil {
+		res = result{Failed: true}
+	}
+
+	r.results[name] = append(r.results[name], res)
+}
+
+func id(n byte) uuid.UUID {
+	return uuid.UUID{15: n}
+}
+
+func str(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+// sortByID puts the rows of a query without an ORDER BY in an order both
+// stores agree on.
+func sortByID[T any](rows []T, key func(T) uuid.UUID) []T {
+	slices.SortFunc(rows, func(a, b T) int {
+		return cmp.Compare(key(a).String(), key(b).String())
+	})
+	return rows
+}
+
+// exercise runs every query in the Querier interface against db, in an order
+// that gives each of them something to find, and records what they return.
+func exercise(db database.Store) map[string][]result {
+	ctx := context.Background()
+	r := &recorder{results: make(map[string][]result)}
+	at := func(hours int) time.Time {
+		return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(hours) * time.Hour)
+	}
+
+	// users and sessions
+	alice, err := db.CreateUser(ctx, database.CreateUserParams{ID: id(1), CreatedAt: at(0), UpdatedAt: at(0), Name: "alice", PasswordHash: str("hash")})
+	r.add("CreateUser", alice, err)
+	bob, err := db.CreateUser(ctx, database.CreateUserParams{ID: id(2), CreatedAt: at(1), UpdatedAt: at(1), Name: "bob"})
+	r.add("CreateUser", bob, err)
+	_, err = db.CreateUser(ctx, database.CreateUserParams{ID: id(3), CreatedAt: at(1), UpdatedAt: at(1), Name: "bob"})
+	r.add("CreateUser", nil, err)
+
+	n, err := db.CountUsers(ctx)
+	r.add("CountUsers", n, err)
+	user, err := db.GetUser(ctx, "alice")
+	r.add("GetUser", user, err)
+	user, err = db.GetUser(ctx, "nobody")
+	r.add("GetUser", user, err)
+	user, err = db.GetUserByID(ctx, bob.ID)
+	r.add("GetUserByID", user, err)
+	users, err := db.GetUsers(ctx)
+	r.add("GetUsers", sortByID(users, func(u database.User) uuid.UUID { return u.ID }), err)
+
+	user, err = db.RenameUser(ctx, database.RenameUserParams{Name: "robert", UpdatedAt: at(2), ID: bob.ID})
+	r.add("RenameUser", user, err)
+	err = db.SetUserPassword(ctx, database.SetUserPasswordParams{PasswordHash: str("other hash"), UpdatedAt: at(3), ID: bob.ID})
+	r.add("SetUserPassword", nil, err)
+	user, err = db.GetUserByID(ctx, bob.ID)
+	r.add("GetUserByID", user, err)
+
+	err = db.CreateSession(ctx, database.CreateSessionParams{ID: id(4), CreatedAt: at(0), UserID: alice.ID, TokenHash: "token", ExpiresAt: at(24)})
+	r.add("CreateSession", nil, err)
+	for _, now := range []time.Time{at(1), at(24), at(25)} {
+		session, err := db.GetSessionByTokenHash(ctx, database.GetSessionByTokenHashParams{TokenHash: "token", ExpiresAt: now})
+		r.add("GetSessionByTokenHash", session, err)
+	}
+	err = db.DeleteSessionsForUser(ctx, alice.ID)
+	r.add("DeleteSessionsForUser", nil, err)
+	session, err := db.GetSessionByTokenHash(ctx, database.GetSessionByTokenHashParams{TokenHash: "token", ExpiresAt: at(1)})
+	r.add("GetSessionByTokenHash", session, err)
+
+	// feeds
+	blog, err := db.CreateFeed(ctx, database.CreateFeedParams{ID: id(10), CreatedAt: at(0), UpdatedAt: at(0), Name: "blog", Url: "https://blog.example.com/rss", UserID: alice.ID})
+	r.add("CreateFeed", blog, err)
+	news, err := db.CreateFeed(ctx, database.CreateFeedParams{ID: id(11), CreatedAt: at(1), UpdatedAt: at(1), Name: "news", Url: "https://news.example.com/rss", UserID: bob.ID})
+	r.add("CreateFeed", news, err)
+
+	f, err := db.GetFeed(ctx, blog.ID)
+	r.add("GetFeed", f, err)
+	f, err = db.GetFeedByURL(ctx, news.Url)
+	r.add("GetFeedByURL", f, err)
+	feeds, err := db.GetFeeds(ctx)
+	r.add("GetFeeds", sortByID(feeds, func(f database.Feed) uuid.UUID { return f.ID }), err)
+	n, err = db.CountFeeds(ctx)
+	r.add("CountFeeds", n, err)
+	n, err = db.CountFeedsForUser(ctx, alice.ID)
+	r.add("CountFeedsForUser", n, err)
+
+	err = db.UpdateFeedMetadata(ctx, database.UpdateFeedMetadataParams{Title: str("The Blog"), SiteUrl: str("https://blog.example.com"), Language: str("en"), ID: blog.ID})
+	r.add("UpdateFeedMetadata", nil, err)
+	err = db.ClearFeedLegacyGUIDs(ctx, blog.ID)
+	r.add("ClearFeedLegacyGUIDs", nil, err)
+	f, err = db.UpdateFeed(ctx, database.UpdateFeedParams{Name: "the news", Url: news.Url, UpdatedAt: at(2), ID: news.ID})
+	r.add("UpdateFeed", f, err)
+
+	err = db.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{LastFetchedAt: sql.NullTime{Time: at(5), Valid: true}, ID: blog.ID})
+	r.add("MarkFeedFetched", nil, err)
+	f, err = db.GetNextFeedToFetch(ctx)
+	r.add("GetNextFeedToFetch", f, err)
+	err = db.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{LastFetchedAt: sql.NullTime{Time: at(6), Valid: true}, ID: news.ID})
+	r.add("MarkFeedFetched", nil, err)
+	f, err = db.GetNextFeedToFetch(ctx)
+	r.add("GetNextFeedToFetch", f, err)
+
+	// follows
+	for i, follow := range []database.CreateFeedFollowParams{
+		{ID: id(20), CreatedAt: at(0), UpdatedAt: at(0), UserID: alice.ID, FeedID: blog.ID},
+		{ID: id(21), CreatedAt: at(1), UpdatedAt: at(1), UserID: alice.ID, FeedID: news.ID},
+		{ID: id(22), CreatedAt: at(2), UpdatedAt: at(2), UserID: bob.ID, FeedID: news.ID},
+		{ID: id(23), CreatedAt: at(3), UpdatedAt: at(3), UserID: bob.ID, FeedID: news.ID},
+	} {
+		row, err := db.CreateFeedFollow(ctx, follow)
+		if i == 3 {
+			// following twice breaks the unique constraint
+			r.add("CreateFeedFollow", nil, err)
+			continue
+		}
+		r.add("CreateFeedFollow", row, err)
+	}
+	follows, err := db.GetFeedFollowsForUser(ctx, "alice")
+	r.add("GetFeedFollowsForUser", sortByID(follows, func(f database.GetFeedFollowsForUserRow) uuid.UUID { return f.ID }), err)
+	n, err = db.CountFeedFollows(ctx)
+	r.add("CountFeedFollows", n, err)
+	n, err = db.CountFeedFollowsForUser(ctx, alice.ID)
+	r.add("CountFeedFollowsForUser", n, err)
+	n, err = db.CountFeedFollowers(ctx, news.ID)
+	r.add("CountFeedFollowers", n, err)
+
+	// posts, including ones without a publish date to check where NULLs sort
+	for i, post := range []database.CreatePostParams{
+		{ID: id(30), CreatedAt: at(10), UpdatedAt: at(10), Title: str("old"), PublishedAt: sql.NullTime{Time: at(-48), Valid: true}, FeedID: blog.ID, Guid: "1"},
+		{ID: id(31), CreatedAt: at(11), UpdatedAt: at(11), Title: str("undated"), FeedID: blog.ID, Guid: "2"},
+		{ID: id(32), CreatedAt: at(12), UpdatedAt: at(12), Title: str("new"), PublishedAt: sql.NullTime{Time: at(-1), Valid: true}, FeedID: blog.ID, Guid: "3", Content: str("<p>hi</p>"), Author: str("alice")},
+		{ID: id(33), CreatedAt: at(13), UpdatedAt: at(13), Title: str("news"), PublishedAt: sql.NullTime{Time: at(-24), Valid: true}, FeedID: news.ID, Guid: "1", Url: str("https://news.example.com/1")},
+		{ID: id(34), CreatedAt: at(14), UpdatedAt: at(14), Title: str("again"), FeedID: blog.ID, Guid: "1"},
+		// ties on the publish date are broken by id, not the order of saving
+		{ID: id(28), CreatedAt: at(15), UpdatedAt: at(15), Title: str("also undated"), FeedID: news.ID, Guid: "2"},
+	} {
+		p, err := db.CreatePost(ctx, post)
+		if i == 4 {
+			// a guid the feed already has is skipped
+			r.add("CreatePost", nil, err)
+			continue
+		}
+		r.add("CreatePost", p, err)
+	}
+
+	p, err := db.GetPostByGUID(ctx, database.GetPostByGUIDParams{FeedID: blog.ID, Guid: "3"})
+	r.add("GetPostByGUID", p, err)
+	err = db.CreatePostRevision(ctx, database.CreatePostRevisionParams{ID: id(40), CreatedAt: at(20), PostID: id(30), Title: str("older")})
+	r.add("CreatePostRevision", nil, err)
+	n, err = db.CountPostRevisions(ctx)
+	r.add("CountPostRevisions", n, err)
+	p, err = db.UpdatePost(ctx, database.UpdatePostParams{UpdatedAt: at(20), Title: str("old, edited"), PublishedAt: sql.NullTime{Time: at(-48), Valid: true}, ID: id(30)})
+	r.add("UpdatePost", p, err)
+	err = db.UpdatePostGUID(ctx, database.UpdatePostGUIDParams{Guid: "4", ID: id(33)})
+	r.add("UpdatePostGUID", nil, err)
+	p, err = db.GetPostByGUID(ctx, database.GetPostByGUIDParams{FeedID: news.ID, Guid: "4"})
+	r.add("GetPostByGUID", p, err)
+
+	for _, name := range []string{"go", "databases", "go"} {
+		err = db.CreatePostCategory(ctx, database.CreatePostCategoryParams{PostID: id(30), Name: name})
+		r.add("CreatePostCategory", nil, err)
+	}
+	err = db.CreatePostCategory(ctx, database.CreatePostCategoryParams{PostID: id(31), Name: "audio"})
+	r.add("CreatePostCategory", nil, err)
+	categories, err := db.GetCategoriesForPost(ctx, id(30))
+	r.add("GetCategoriesForPost", categories, err)
+
+	for _, limit := range []int32{10, 2} {
+		posts, err := db.GetPostsForUser(ctx, database.GetPostsForUserParams{UserID: alice.ID, Limit: limit, Offset: 1})
+		r.add("GetPostsForUser", posts, err)
+		pageCategories, err := db.GetCategoriesForPostsPage(ctx, database.GetCategoriesForPostsPageParams{UserID: alice.ID, Limit: limit, Offset: 1})
+		r.add("GetCategoriesForPostsPage", pageCategories, err)
+	}
+	err = db.DeletePostCategories(ctx, id(30))
+	r.add("DeletePostCategories", nil, err)
+	categories, err = db.GetCategoriesForPost(ctx, id(30))
+	r.add("GetCategoriesForPost", categories, err)
+	since, err := db.GetPostsForUserSince(ctx, database.GetPostsForUserSinceParams{UserID: alice.ID, CreatedAt: at(11)})
+	r.add("GetPostsForUserSince", since, err)
+	n, err = db.CountPosts(ctx)
+	r.add("CountPosts", n, err)
+	n, err = db.CountPostsForUser(ctx, bob.ID)
+	r.add("CountPostsForUser", n, err)
+	n, err = db.CountPostsForFeed(ctx, blog.ID)
+	r.add("CountPostsForFeed", n, err)
+
+	// media and downloads
+	for i, postID := range []uuid.UUID{id(31), id(32), id(32)} {
+		err = db.CreatePostEnclosure(ctx, database.CreatePostEnclosureParams{
+			ID:        id(50 + byte(i)),
+			CreatedAt: at(30 + i),
+			PostID:    postID,
+			Url:       "https://blog.example.com/" + strings.Repeat("a", i+1) + ".mp3",
+			Source:    "enclosure",
+			MediaType: str("audio/mpeg"),
+			Length:    sql.NullInt64{Int64: int64(i * 100), Valid: i != 0},
+		})
+		r.add("CreatePostEnclosure", nil, err)
+	}
+	enclosures, err := db.GetEnclosuresForPost(ctx, id(32))
+	r.add("GetEnclosuresForPost", enclosures, err)
+	for _, offset := range []int32{0, 2} {
+		enclosures, err = db.GetEnclosuresForPostsPage(ctx, database.GetEnclosuresForPostsPageParams{UserID: alice.ID, Limit: 2, Offset: offset})
+		r.add("GetEnclosuresForPostsPage", enclosures, err)
+	}
+	userEnclosures, err := db.GetEnclosuresForUser(ctx, database.GetEnclosuresForUserParams{UserID: alice.ID, CreatedAt: at(0)})
+	r.add("GetEnclosuresForUser", userEnclosures, err)
+
+	download, err := db.CreateDownload(ctx, database.CreateDownloadParams{ID: id(60), CreatedAt: at(40), EnclosureID: id(50), Path: "/tmp/a.mp3", Size: 3, Sha256: "abc"})
+	r.add("CreateDownload", download, err)
+	download, err = db.GetDownloadByPath(ctx, "/tmp/a.mp3")
+	r.add("GetDownloadByPath", download, err)
+	_, err = db.CreateDownload(ctx, database.CreateDownloadParams{ID: id(61), CreatedAt: at(39), EnclosureID: id(50), Path: "/tmp/b/a.mp3", Size: 3, Sha256: "abc"})
+	r.add("CreateDownload", nil, err)
+	downloads, err := db.GetDownloadsForEnclosure(ctx, id(50))
+	r.add("GetDownloadsForEnclosure", downloads, err)
+	n, err = db.CountDownloads(ctx)
+	r.add("CountDownloads", n, err)
+	n, err = db.CountPostEnclosures(ctx)
+	r.add("CountPostEnclosures", n, err)
+
+	// filters and webhooks
+	filter, err := db.CreateFilter(ctx, database.CreateFilterParams{ID: id(70), CreatedAt: at(0), UpdatedAt: at(0), UserID: alice.ID, FeedID: uuid.NullUUID{UUID: blog.ID, Valid: true}, Action: "hide", Field: "title", Pattern: "ad"})
+	r.add("CreateFilter", filter, err)
+	_, err = db.CreateFilter(ctx, database.CreateFilterParams{ID: id(71), CreatedAt: at(1), UpdatedAt: at(1), UserID: alice.ID, Action: "shout", Field: "title", Pattern: "ad"})
+	r.add("CreateFilter", nil, err)
+	filters, err := db.GetFiltersForUser(ctx, alice.ID)
+	r.add("GetFiltersForUser", filters, err)
+	filterCounts, err := db.CountFilters(ctx)
+	r.add("CountFilters", filterCounts, err)
+	err = db.DeleteFilter(ctx, database.DeleteFilterParams{ID: id(70), UserID: bob.ID})
+	r.add("DeleteFilter", nil, err)
+	err = db.DeleteFilter(ctx, database.DeleteFilterParams{ID: id(70), UserID: alice.ID})
+	r.add("DeleteFilter", nil, err)
+	filters, err = db.GetFiltersForUser(ctx, alice.ID)
+	r.add("GetFiltersForUser", filters, err)
+
+	for i, hook := range []database.CreateWebhookParams{
+		{ID: id(80), CreatedAt: at(0), UpdatedAt: at(0), UserID: alice.ID, Url: "https://hooks.example.com/all"},
+		{ID: id(81), CreatedAt: at(1), UpdatedAt: at(1), UserID: alice.ID, FeedID: uuid.NullUUID{UUID: news.ID, Valid: true}, Url: "https://hooks.example.com/news", Keyword: str("launch")},
+		{ID: id(82), CreatedAt: at(2), UpdatedAt: at(2), UserID: bob.ID, FeedID: uuid.NullUUID{UUID: blog.ID, Valid: true}, Url: "https://hooks.example.com/bob"},
+	} {
+		w, err := db.CreateWebhook(ctx, hook)
+		r.add("CreateWebhook", w, err)
+		if i == 0 {
+			err = db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{ID: id(90), CreatedAt: at(50), WebhookID: w.ID, PostID: id(32), Attempt: 1, StatusCode: sql.NullInt32{Int32: 500, Valid: true}, Error: str("500 Internal Server Error")})
+			r.add("CreateWebhookDelivery", nil, err)
+			err = db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{ID: id(91), CreatedAt: at(51), WebhookID: w.ID, PostID: id(32), Attempt: 2, StatusCode: sql.NullInt32{Int32: 200, Valid: true}, Succeeded: true})
+			r.add("CreateWebhookDelivery", nil, err)
+		}
+	}
+	hooks, err := db.GetWebhooksForUser(ctx, alice.ID)
+	r.add("GetWebhooksForUser", hooks, err)
+	webhookCounts, err := db.CountWebhooks(ctx)
+	r.add("CountWebhooks", webhookCounts, err)
+	for _, feedID := range []uuid.UUID{blog.ID, news.ID} {
+		hooks, err = db.GetWebhooksForFeed(ctx, uuid.NullUUID{UUID: feedID, Valid: true})
+		r.add("GetWebhooksForFeed", sortByID(hooks, func(w database.Webhook) uuid.UUID { return w.ID }), err)
+	}
+	deliveries, err := db.GetWebhookDeliveriesForUser(ctx, database.GetWebhookDeliveriesForUserParams{UserID: alice.ID, Limit: 5})
+	r.add("GetWebhookDeliveriesForUser", deliveries, err)
+	err = db.DeleteWebhook(ctx, database.DeleteWebhookParams{ID: id(80), UserID: alice.ID})
+	r.add("DeleteWebhook", nil, err)
+	deliveries, err = db.GetWebhookDeliveriesForUser(ctx, database.GetWebhookDeliveriesForUserParams{UserID: alice.ID, Limit: 5})
+	r.add("GetWebhookDeliveriesForUser", deliveries, err)
+
+	// unfollowing, handing over and deleting
+	feedID, err := db.UnfollowUserFromFeed(ctx, database.UnfollowUserFromFeedParams{Url: news.Url, UserID: alice.ID})
+	r.add("UnfollowUserFromFeed", feedID, err)
+	feedID, err = db.UnfollowUserFromFeed(ctx, database.UnfollowUserFromFeedParams{Url: news.Url, UserID: alice.ID})
+	r.add("UnfollowUserFromFeed", feedID, err)
+
+	err = db.TransferFeeds(ctx, database.TransferFeedsParams{UserID: alice.ID, UpdatedAt: at(60), UserID_2: bob.ID})
+	r.add("TransferFeeds", nil, err)
+	n, err = db.CountFeedsForUser(ctx, alice.ID)
+	r.add("CountFeedsForUser", n, err)
+
+	err = db.DeleteUser(ctx, bob.ID)
+	r.add("DeleteUser", nil, err)
+	n, err = db.CountFeedFollows(ctx)
+	r.add("CountFeedFollows", n, err)
+
+	err = db.DeleteFeed(ctx, news.ID)
+	r.add("DeleteFeed", nil, err)
+	webhookCounts, err = db.CountWebhooks(ctx)
+	r.add("CountWebhooks", webhookCounts, err)
+	n, err = db.CountPosts(ctx)
+	r.add("CountPosts", n, err)
+
+	err = db.DeleteAllPosts(ctx)
+	r.add("DeleteAllPosts", nil, err)
+	download, err = db.GetDownloadByPath(ctx, "/tmp/a.mp3")
+	r.add("GetDownloadByPath", download, err)
+
+	err = db.DeleteAllFeeds(ctx)
+	r.add("DeleteAllFeeds", nil, err)
+	n, err = db.CountFeeds(ctx)
+	r.add("CountFeeds", n, err)
+
+	err = db.ResetUsers(ctx)
+	r.add("ResetUsers", nil, err)
+	n, err = db.CountUsers(ctx)
+	r.add("CountUsers", n, err)
+
+	return r.results
+}
+
+// TestQueriesOnSQLite runs every query against a migrated SQLite database
+// and checks it answers the same as the in-memory store, which follows
+// PostgreSQL's rules.
+func TestQueriesOnSQLite(t *testing.T) {
+	_, queries := openSQLite(t)
+
+	got := exercise(queries)
+	want := exercise(memory.New())
+
+	querier := reflect.TypeFor[database.Querier]()
+	for i := range querier.NumMethod() {
+		name := querier.Method(i).Name
+		if _, ok := got[name]; !ok {
+			t.Errorf("%s isn't exercised", name)
+		}
+	}
+
+	for name, results := range want {
+		for i, res := range results {
+			if !reflect.DeepEqual(got[name][i], res) {
+				t.Errorf("%s call %d:\nsqlite: %+v\nmemory: %+v", name, i+1, got[name][i], res)
+			}
+		}
+	}
+}
+
+// TestSQLiteSchemaMatchesModels checks that the squashed SQLite schema has
+// the columns the PostgreSQL migrations do, as the generated models record
+// them.
+func TestSQLiteSchemaMatchesModels(t *testing.T) {
+	db, _ := openSQLite(t)
+
+	for table, model := range map[string]any{
+		"users":              database.User{},
+		"sessions":           database.Session{},
+		"feeds":              database.Feed{},
+		"feed_follows":       database.FeedFollow{},
+		"posts":              database.Post{},
+		"post_revisions":     database.PostRevision{},
+		"post_categories":    database.PostCategory{},
+		"post_enclosures":    database.PostEnclosure{},
+		"downloads":          database.Download{},
+		"filters":            database.Filter{},
+		"webhooks":           database.Webhook{},
+		"webhook_deliveries": database.WebhookDelivery{},
+	} {
+		rows, err := db.Query("SELECT * FROM " + table + " LIMIT 0")
+		if err != nil {
+			t.Errorf("%s: %v", table, err)
+			continue
+		}
+		columns, err := rows.Columns()
+		rows.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		modelType := reflect.TypeOf(model)
+		var fields []string
+		for i := range modelType.NumField() {
+			fields = append(fields, snakeCase(modelType.Field(i).Name))
+		}
+
+		if !slices.Equal(columns, fields) {
+			t.Errorf("%s has columns %q in SQLite, but %q in PostgreSQL", table, columns, fields)
+		}
+	}
+}
+
+// snakeCase undoes sqlc's naming of columns, e.g. SiteUrl and ID back to
+// site_url and id.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 && !(name[i-1] >= 'A' && name[i-1] <= 'Z') {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+
+	return strings.ToLower(b.String())
+}
+
+func TestInTx(t *testing.T) {
+	_, queries := openSQLite(t)
+
+	for name, db := range map[string]database.Store{"sqlite": queries, "memory": memory.New()} {
+		ctx := context.Background()
+		add := func(tx database.Store, n byte, userName string) error {
+			_, err := tx.CreateUser(ctx, database.CreateUserParams{ID: id(n), CreatedAt: time.Now(), UpdatedAt: time.Now(), Name: userName})
+			return err
+		}
+
+		err := db.InTx(ctx, func(tx database.Store) error {
+			err := add(tx, 1, "alice")
+			if err != nil {
+				return err
+			}
+			// a transaction inside one is part of it
+			return tx.InTx(ctx, func(tx database.Store) error { return add(tx, 2, "alice") })
+		})
+		if err == nil {
+			t.Errorf("%s: added two users called alice", name)
+		}
+
+		err = db.InTx(ctx, func(tx database.Store) error { return add(tx, 3, "bob") })
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		users, err := db.GetUsers(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 1 || users[0].Name != "bob" {
+			t.Errorf("%s: got users %+v, want only bob", name, users)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Store is everything gator needs from its database. Queries implements it
+// on top of PostgreSQL or SQLite, and memory.Store keeps everything in memory
+// for tests and ephemeral sessions.
+type Store interface {
+	Querier
+
+	// InTx runs fn with a Store whose changes are all kept if fn returns
+	// nil, and all undone if it returns an error.
+	InTx(ctx context.Context, fn func(Store) error) error
+}
+
+// InTx runs fn in a transaction. Queries already in one run fn in the same
+// transaction.
+func (q *Queries) InTx(ctx context.Context, fn func(Store) error) error {
+	var (
+		tx  *sql.Tx
+		err error
+		txq *Queries
+	)
+	switch db := q.db.(type) {
+	case *sql.DB:
+		tx, err = db.BeginTx(ctx, nil)
+		txq = &Queries{db: tx}
+	case sqliteDB:
+		conn, ok := db.db.(*sql.DB)
+		if !ok {
+			return fn(q)
+		}
+		tx, err = conn.BeginTx(ctx, nil)
+		txq = &Queries{db: sqliteDB{db: tx}}
+	default:
+		return fn(q)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = fn(txq)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
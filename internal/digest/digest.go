@@ -0,0 +1,193 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+type Post struct {
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+}
+
+type FeedGroup struct {
+	Name  string
+	Url   string
+	Posts []Post
+}
+
+type Digest struct {
+	UserName string
+	Since    time.Time
+	Until    time.Time
+	Feeds    []FeedGroup
+}
+
+func (d Digest) PostCount() int {
+	n := 0
+	for _, f := range d.Feeds {
+		n += len(f.Posts)
+	}
+
+	return n
+}
+
+func (d Digest) Subject() string {
+	return fmt.Sprintf("Gator digest for %s: %d new posts", d.UserName, d.PostCount())
+}
+
+const textLayout = `New posts for {{.UserName}} since {{.Since.Format "Mon, 02 Jan 2006 15:04"}}
+{{range .Feeds}}
+== {{.Name}} ==
+{{range .Posts}}
+* {{.Title}}
+  {{.Url}}
+{{end}}{{else}}
+Nothing new.
+{{end}}`
+
+const htmlLayout = `<!DOCTYPE html>
+<html>
+<body>
+<h1>New posts for {{.UserName}}</h1>
+<p>Since {{.Since.Format "Mon, 02 Jan 2006 15:04"}}</p>
+{{range .Feeds}}
+<h2><a href="{{.Url}}">{{.Name}}</a></h2>
+<ul>
+{{range .Posts}}<li><a href="{{.Url}}">{{.Title}}</a>{{if not .PublishedAt.IsZero}} <small>{{.PublishedAt.Format "02 Jan 15:04"}}</small>{{end}}</li>
+{{end}}</ul>
+{{else}}
+<p>Nothing new.</p>
+{{end}}
+</body>
+</html>
+`
+
+var (
+	textTmpl = texttemplate.Must(texttemplate.New("text").Parse(textLayout))
+	htmlTmpl = htmltemplate.Must(htmltemplate.New("html").Parse(htmlLayout))
+)
+
+func (d Digest) Text() (string, error) {
+	var buf bytes.Buffer
+	err := textTmpl.Execute(&buf, d)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (d Digest) HTML() (string, error) {
+	var buf bytes.Buffer
+	err := htmlTmpl.Execute(&buf, d)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Message renders the digest as a multipart/alternative email with CRLF line
+// endings, ready to be sent or saved as an .eml file.
+func (d Digest) Message(from, to string) ([]byte, error) {
+	text, err := d.Text()
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := d.HTML()
+	if err != nil {
+		return nil, err
+	}
+
+	boundary, err := randomString(30)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomString(12)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	err = mw.SetBoundary(boundary)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		_, err = qp.Write([]byte(strings.ReplaceAll(part.content, "\n", "\r\n")))
+		if err != nil {
+			return nil, err
+		}
+		err = qp.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.Subject()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", d.Until.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@gator>\r\n", id)
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n", mw.Boundary())
+	fmt.Fprintf(&msg, "\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// random is where message IDs and MIME boundaries come from, swapped out in
+// tests so messages come out the same every time.
+var random io.Reader = rand.Reader
+
+// randomString returns n random bytes as URL safe base64, which is also
+// fine as a MIME boundary.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(random, b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
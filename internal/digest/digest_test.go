@@ -0,0 +1,212 @@
+package digest
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// zeros stands in for crypto/rand, so messages come out the same every time.
+type zeros struct{}
+
+func (zeros) Read(b []byte) (int, error) {
+	clear(b)
+	return len(b), nil
+}
+
+func init() {
+	random = zeros{}
+}
+
+func testDigest() Digest {
+	since := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	return Digest{
+		UserName: "alice",
+		Since:    since,
+		Until:    since.Add(24 * time.Hour),
+		Feeds: []FeedGroup{
+			{
+				Name: "Café notes",
+				Url:  "https://blog.example.com/rss.xml",
+				Posts: []Post{
+					{Title: "Crème brûlée & <more>", Url: "https://blog.example.com/1", PublishedAt: since.Add(time.Hour)},
+					// long enough that quoted-printable has to wrap it
+					{Title: strings.Repeat("A very long title, ", 6), Url: "https://blog.example.com/2?a=1&b=2"},
+				},
+			},
+			{
+				Name:  "News",
+				Url:   "https://news.example.com/atom.xml",
+				Posts: []Post{{Title: "Launch day", Url: "https://news.example.com/launch"}},
+			},
+		},
+	}
+}
+
+// golden compares got with testdata/name, or rewrites it with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		err := os.WriteFile(path, got, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s changed, run go test -update if that's intended; got:\n%s", name, got)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	msg, err := testDigest().Message("gator@example.com", "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "digest.eml", msg)
+}
+
+func TestMessagePartsDecodeToTheDigest(t *testing.T) {
+	d := testDigest()
+	msg, err := d.Message("gator@example.com", "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != d.Subject() {
+		t.Errorf("got subject %q, want %q", subject, d.Subject())
+	}
+
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("got a %s message", mediaType)
+	}
+
+	text, err := d.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	html, err := d.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := multipart.NewReader(m.Body, params["boundary"])
+	for _, want := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		// NextRawPart, since NextPart would decode the quoted-printable itself
+		part, err := r.NextRawPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := part.Header.Get("Content-Type"); ct != want.contentType {
+			t.Errorf("got a %s part, want %s", ct, want.contentType)
+		}
+
+		raw, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for line := range strings.SplitSeq(string(raw), "\r\n") {
+			if len(line) > 76 {
+				t.Errorf("line longer than quoted-printable allows: %q", line)
+			}
+		}
+
+		decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(raw)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.ReplaceAll(string(decoded), "\r\n", "\n"); got != want.content {
+			t.Errorf("%s part decodes to %q, want %q", want.contentType, got, want.content)
+		}
+	}
+
+	if _, err := r.NextRawPart(); err != io.EOF {
+		t.Errorf("got another part after text and HTML: %v", err)
+	}
+}
+
+func TestAppendMbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digests.mbox")
+	date := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	// lines looking like the start of a message get quoted, and quoted ones
+	// get another level so readers can undo it
+	for _, msg := range []string{
+		"Subject: first\r\n\r\nFrom the start\r\n>From quoted\r\n>>From twice\r\nFromage\r\n",
+		"Subject: second\r\n\r\nNothing to quote\r\n",
+	} {
+		err := AppendMbox(path, "gator@example.com", date, []byte(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mbox, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "digests.mbox", mbox)
+
+	checkPrivate(t, path)
+}
+
+func TestWriteEML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digest.eml")
+
+	err := WriteEML(path, []byte("Subject: hi\r\n\r\nhello\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkPrivate(t, path)
+}
+
+func checkPrivate(t *testing.T, path string) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		t.Errorf("%s has mode %v, want it private", filepath.Base(path), mode)
+	}
+}
@@ -0,0 +1,77 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// WriteEML saves msg to path, readable only by the user, since digests list
+// what they read and who they are.
+func WriteEML(path string, msg []byte) error {
+	return os.WriteFile(path, msg, 0600)
+}
+
+// AppendMbox adds msg to an mboxrd file, creating it readable only by the
+// user if needed.
+func AppendMbox(path, from string, date time.Time, msg []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From %s %s\n", from, date.UTC().Format(time.ANSIC))
+
+	msg = bytes.ReplaceAll(msg, []byte("\r\n"), []byte("\n"))
+	// the blank line after the message separates it from the next one, so
+	// it mustn't add one of its own
+	msg = bytes.TrimSuffix(msg, []byte("\n"))
+
+	for _, line := range bytes.Split(msg, []byte("\n")) {
+		if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			buf.WriteByte('>')
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	_, err = f.Write(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func Send(cfg SMTPConfig, from, to string, msg []byte) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("no SMTP host configured")
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return smtp.SendMail(addr, auth, from, []string{to}, msg)
+}
@@ -0,0 +1,293 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var ErrTooLarge = errors.New("file is larger than the size limit")
+
+// Fetch downloads fileURL to dest. Data is written to dest.part first, so an
+// interrupted download picks up where it left off on the next run, as long as
+// the file hasn't changed on the server in the meantime. A maxSize of zero
+// means no limit. It returns the size and sha256 of the finished file.
+func Fetch(ctx context.Context, fileURL, dest string, maxSize int64) (int64, string, error) {
+	partial := dest + ".part"
+	// the ETag or Last-Modified date of the file dest.part is a piece of
+	validatorFile := partial + ".validator"
+
+	offset := int64(0)
+	validator := ""
+	info, err := os.Stat(partial)
+	if err == nil {
+		v, err := os.ReadFile(validatorFile)
+		// without a validator there's no telling whether the rest of the file
+		// goes with the part already downloaded, so start over
+		if err == nil && len(v) > 0 {
+			offset = info.Size()
+			validator = string(v)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// if the file has changed, this gets all of it rather than the rest of it
+		req.Header.Set("If-Range", validator)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		first, _, err := contentRange(res.Header.Get("Content-Range"))
+		if err != nil || first != offset {
+			// appending anything but the rest of the file would corrupt it
+			os.Remove(partial)
+			os.Remove(validatorFile)
+			return 0, "", fmt.Errorf("download of %s resumed at the wrong place (Content-Range %q), it will start over next time", fileURL, res.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// the server ignored the range or the file changed, so start over
+		offset = 0
+		flags |= os.O_TRUNC
+
+		err = saveValidator(validatorFile, res)
+		if err != nil {
+			return 0, "", err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is complete if the range starts at the end of the
+		// file, but a 416 for any other reason leaves nothing to go on
+		_, total, err := contentRange(res.Header.Get("Content-Range"))
+		if offset == 0 || err != nil || total != offset {
+			os.Remove(partial)
+			os.Remove(validatorFile)
+			return 0, "", fmt.Errorf("download of %s can't be resumed (%s), it will start over next time", fileURL, res.Status)
+		}
+	default:
+		return 0, "", fmt.Errorf("download of %s failed with status %s", fileURL, res.Status)
+	}
+
+	if maxSize > 0 && res.ContentLength > 0 && offset+res.ContentLength > maxSize {
+		os.Remove(partial)
+		os.Remove(validatorFile)
+		return 0, "", ErrTooLarge
+	}
+
+	if res.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		f, err := os.OpenFile(partial, flags, 0644)
+		if err != nil {
+			return 0, "", err
+		}
+
+		var body io.Reader = res.Body
+		if maxSize > 0 {
+			// read one byte past the limit so an oversized body can be detected
+			body = io.LimitReader(res.Body, maxSize-offset+1)
+		}
+
+		n, err := io.Copy(f, body)
+		closeErr := f.Close()
+		if err != nil {
+			return 0, "", err
+		}
+		if closeErr != nil {
+			return 0, "", closeErr
+		}
+		if maxSize > 0 && offset+n > maxSize {
+			os.Remove(partial)
+			os.Remove(validatorFile)
+			return 0, "", ErrTooLarge
+		}
+	}
+
+	size, sum, err := Checksum(partial)
+	if err != nil {
+		return 0, "", err
+	}
+
+	err = os.Rename(partial, dest)
+	if err != nil {
+		return 0, "", err
+	}
+	os.Remove(validatorFile)
+
+	return size, sum, nil
+}
+
+// contentRange reads the first byte and the total size of the file from a
+// Content-Range header, like "bytes 100-199/200", or "bytes */200" when the
+// requested range couldn't be served, which has no first byte. Either is -1
+// when the header doesn't give it.
+func contentRange(header string) (first, total int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+	}
+	byteRange, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+	}
+
+	first, total = -1, -1
+	if byteRange != "*" {
+		start, _, ok := strings.Cut(byteRange, "-")
+		if !ok {
+			return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+		}
+		first, err = strconv.ParseInt(start, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+		}
+	}
+	if size != "*" {
+		total, err = strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range '%s'", header)
+		}
+	}
+
+	return first, total, nil
+}
+
+// saveValidator records what identifies the version of the file res is
+// sending, for If-Range to check when resuming. Weak ETags can't be used for
+// that, so those fall back to Last-Modified.
+func saveValidator(validatorFile string, res *http.Response) error {
+	validator := res.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = res.Header.Get("Last-Modified")
+	}
+
+	if validator == "" {
+		err := os.Remove(validatorFile)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	return os.WriteFile(validatorFile, []byte(validator), 0644)
+}
+
+func Checksum(filePath string) (int64, string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FileName picks a name for the file at fileURL, based on the last element
+// of its path.
+func FileName(fileURL, fallback string) string {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return SafeName(fallback)
+	}
+
+	name := SafeName(path.Base(u.Path))
+	if name == "" || name == "." || name == "/" {
+		return SafeName(fallback)
+	}
+
+	return name
+}
+
+// SafeName makes s usable as a single path element.
+func SafeName(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 32 {
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.Trim(s, ". ")
+}
+
+// ParseSize reads sizes like "500MB", "1.5G" or plain byte counts. It works
+// in whole bytes throughout, so large sizes aren't rounded.
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	s = strings.TrimSuffix(s, "B")
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"K", 1 << 10},
+		{"M", 1 << 20},
+		{"G", 1 << 30},
+		{"T", 1 << 40},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.size
+			s = strings.TrimSuffix(s, unit.suffix)
+			break
+		}
+	}
+
+	invalid := fmt.Errorf("invalid size '%s'", size)
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	n, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil || n > math.MaxInt64/uint64(multiplier) {
+		return 0, invalid
+	}
+	bytes := int64(n) * multiplier
+
+	if fraction != "" {
+		// fraction is a fraction of a unit: fraction * multiplier / 10^digits
+		extra, ok := new(big.Int).SetString(fraction, 10)
+		if !ok || strings.ContainsAny(fraction, "+-") {
+			return 0, invalid
+		}
+		extra.Mul(extra, big.NewInt(multiplier))
+		extra.Quo(extra, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(len(fraction))), nil))
+		if !extra.IsInt64() || bytes > math.MaxInt64-extra.Int64() {
+			return 0, invalid
+		}
+		bytes += extra.Int64()
+	}
+
+	return bytes, nil
+}
@@ -0,0 +1,206 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var episode = []byte(strings.Repeat("0123456789", 100))
+
+// serveEpisode serves episode with ranges and If-Range, like most servers do.
+func serveEpisode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("ETag", `"v1"`)
+	http.ServeContent(w, r, "episode.mp3", time.Time{}, bytes.NewReader(episode))
+}
+
+// interrupted leaves the first n bytes of episode in dest.part, as a
+// download cut short would.
+func interrupted(t *testing.T, dest string, n int, validator string) {
+	t.Helper()
+
+	err := os.WriteFile(dest+".part", episode[:n], 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(dest+".part.validator", []byte(validator), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkGone(t *testing.T, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s is still there", filepath.Base(path))
+		}
+	}
+}
+
+func TestFetchResumes(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		validator string
+		requested string
+	}{
+		{"the same file", `"v1"`, "bytes=400-"},
+		{"a changed file from the start", `"v0"`, "bytes=400-"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ranges []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ranges = append(ranges, r.Header.Get("Range"))
+				serveEpisode(w, r)
+			}))
+			defer srv.Close()
+			dest := filepath.Join(t.TempDir(), "episode.mp3")
+			interrupted(t, dest, 400, tc.validator)
+
+			size, _, err := Fetch(context.Background(), srv.URL, dest, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != int64(len(episode)) || !bytes.Equal(got, episode) {
+				t.Errorf("got %d bytes that don't match the episode", size)
+			}
+			if len(ranges) != 1 || ranges[0] != tc.requested {
+				t.Errorf("requested ranges %q", ranges)
+			}
+			checkGone(t, dest+".part", dest+".part.validator")
+		})
+	}
+}
+
+func TestFetchChecksWhereTheServerResumes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the range asked for isn't the one sent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-99/%d", len(episode)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(episode[:100])
+	}))
+	defer srv.Close()
+	dest := filepath.Join(t.TempDir(), "episode.mp3")
+	interrupted(t, dest, 400, `"v1"`)
+
+	_, _, err := Fetch(context.Background(), srv.URL, dest, 0)
+	if err == nil {
+		t.Fatal("appended the wrong part of the file")
+	}
+	checkGone(t, dest, dest+".part", dest+".part.validator")
+}
+
+func TestFetchFinishesCompleteParts(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		partSize int
+		total    string
+		finished bool
+	}{
+		{"when the server says it's complete", len(episode), fmt.Sprint(len(episode)), true},
+		{"not when the server has more", 400, fmt.Sprint(len(episode)), false},
+		{"not when the server has less", len(episode), "10", false},
+		{"not when the server doesn't say", len(episode), "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.total != "" {
+					w.Header().Set("Content-Range", "bytes */"+tc.total)
+				}
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			}))
+			defer srv.Close()
+			dest := filepath.Join(t.TempDir(), "episode.mp3")
+			interrupted(t, dest, tc.partSize, `"v1"`)
+
+			_, _, err := Fetch(context.Background(), srv.URL, dest, 0)
+			if tc.finished {
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkGone(t, dest+".part", dest+".part.validator")
+				return
+			}
+
+			if err == nil {
+				t.Fatal("took an incomplete part for the whole file")
+			}
+			checkGone(t, dest, dest+".part", dest+".part.validator")
+		})
+	}
+}
+
+func TestFetchLimitsTheSize(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"by Content-Length", serveEpisode},
+		{"without a Content-Length", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			w.Write(episode)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+			dest := filepath.Join(t.TempDir(), "episode.mp3")
+			interrupted(t, dest, 400, `"v0"`)
+
+			_, _, err := Fetch(context.Background(), srv.URL, dest, 500)
+			if !errors.Is(err, ErrTooLarge) {
+				t.Fatalf("got error %v, want ErrTooLarge", err)
+			}
+			checkGone(t, dest, dest+".part", dest+".part.validator")
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for _, tc := range []struct {
+		size string
+		want int64
+	}{
+		{"1024", 1024},
+		{"500MB", 500 << 20},
+		{"2g", 2 << 30},
+		{" 10KB ", 10 << 10},
+		{"1.5G", 3 << 29},
+		{"0.1K", 102},
+		// too precise for a float64 to hold
+		{"8191.999999999999999T", 1<<53 - 1},
+		{"8191T", 8191 << 40},
+	} {
+		got, err := ParseSize(tc.size)
+		if err != nil {
+			t.Errorf("ParseSize(%q): %v", tc.size, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tc.size, got, tc.want)
+		}
+	}
+
+	for _, size := range []string{"", "MB", "-1", "+1", "1.-5", "1e3", "1.5.5", "8388608T", "huge"} {
+		if _, err := ParseSize(size); err == nil {
+			t.Errorf("ParseSize(%q) accepted an invalid size", size)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A cassette is a recorded response to a feed fetch, saved as JSON so a
+// scrape can be replayed later without the network. Body is kept as bytes
+// (base64 in the JSON) since feeds aren't always valid UTF-8.
+type cassette struct {
+	URL        string    `json:"url"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Body       []byte    `json:"body"`
+}
+
+// cassettePath names the cassette for feedURL after a hash of the url, since
+// urls rarely make good file names.
+func cassettePath(dir, feedURL string) string {
+	sum := sha256.Sum256([]byte(feedURL))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Recorder fetches feeds with Fetcher (DefaultFetcher if nil) and saves a
+// cassette of every successful response in Dir, replacing older ones.
+type Recorder struct {
+	Dir     string
+	Fetcher Fetcher
+}
+
+func (r Recorder) Fetch(ctx context.Context, feedURL string) ([]byte, error) {
+	fetcher := r.Fetcher
+	if fetcher == nil {
+		fetcher = DefaultFetcher{}
+	}
+
+	data, err := fetcher.Fetch(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(r.Dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(cassettePath(r.Dir, feedURL))
+	if err != nil {
+		return nil, err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(cassette{
+		URL:        feedURL,
+		RecordedAt: time.Now().UTC(),
+		Body:       data,
+	})
+	closeErr := f.Close()
+	if err != nil {
+		return nil, err
+	}
+	if closeErr != nil {
+		return nil, closeErr
+	}
+
+	return data, nil
+}
+
+// Replayer answers fetches from the cassettes in Dir, never touching the
+// network.
+type Replayer struct {
+	Dir string
+}
+
+func (r Replayer) Fetch(ctx context.Context, feedURL string) ([]byte, error) {
+	data, err := os.ReadFile(cassettePath(r.Dir, feedURL))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no recording of %s in %s", feedURL, r.Dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c cassette
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, fmt.Errorf("reading recording of %s: %w", feedURL, err)
+	}
+
+	return c.Body, nil
+}
@@ -0,0 +1,256 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// testdata/cassettes holds recordings of these feeds
+const (
+	blogURL = "https://blog.example.com/rss.xml"
+	newsURL = "https://news.example.com/atom.xml"
+
+	// modelled on a WordPress blog, a static site generator's Atom feed and
+	// a podcast host's feed, with everything they usually put in
+	wordpressURL = "https://gardennotes.example.org/feed/"
+	atomBlogURL  = "https://compilerdiaries.example.net/atom.xml"
+	podcastURL   = "https://feeds.example.fm/late-shift"
+)
+
+// replayFeeds has user follow the recorded feeds at urls and returns a
+// scraper that replays them.
+func replayFeeds(t *testing.T, urls ...string) (Scraper, database.User) {
+	t.Helper()
+
+	sc, _, user, _ := newTestScraper(t)
+	sc.Fetcher = Replayer{Dir: "testdata/cassettes"}
+	ctx := context.Background()
+	now := time.Time(sc.Clock.(FixedClock))
+
+	// the feed newTestScraper made has no recording, and is fetched first
+	for _, url := range urls {
+		f, err := sc.DB.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      url,
+			Url:       url,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = sc.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    user.ID,
+			FeedID:    f.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return sc, user
+}
+
+func TestReplayerScrapesRecordedFeeds(t *testing.T) {
+	sc, user := replayFeeds(t, blogURL, newsURL)
+	ctx := context.Background()
+	now := time.Time(sc.Clock.(FixedClock))
+
+	for range 3 {
+		scrape(t, sc)
+	}
+
+	posts := postsFor(t, sc.DB, user)
+	var titles []string
+	for _, post := range posts {
+		titles = append(titles, post.Title.String)
+		if !post.CreatedAt.Equal(now) {
+			t.Errorf("post %q was saved at %v, not the clock's %v", post.Title.String, post.CreatedAt, now)
+		}
+	}
+
+	want := []string{"Second post", "First post ☕", "Launch day"}
+	if len(titles) != len(want) {
+		t.Fatalf("got posts %q, want %q", titles, want)
+	}
+	for _, title := range want {
+		if !slices.Contains(titles, title) {
+			t.Errorf("got posts %q, want %q among them", titles, title)
+		}
+	}
+
+	blog, err := sc.DB.GetFeedByURL(ctx, blogURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blog.Description.String != "Notes from the café" {
+		t.Errorf("got blog description %q", blog.Description.String)
+	}
+
+	first, err := sc.DB.GetPostByGUID(ctx, database.GetPostByGUIDParams{FeedID: blog.ID, Guid: "post-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Description.String != "Crème brûlée & more" {
+		t.Errorf("got description %q", first.Description.String)
+	}
+	if !blog.LastFetchedAt.Time.Equal(now) {
+		t.Errorf("blog was fetched at %v, not the clock's %v", blog.LastFetchedAt.Time, now)
+	}
+}
+
+func TestRecorderKeepsBytesIntact(t *testing.T) {
+	dir := t.TempDir()
+	// a Latin-1 feed isn't valid UTF-8
+	body := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><title>Caf\xe9</title></channel></rss>")
+
+	_, err := Recorder{Dir: dir, Fetcher: fakeFetcher{testFeedURL: string(body)}}.Fetch(context.Background(), testFeedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := Replayer{Dir: dir}.Fetch(context.Background(), testFeedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(replayed, body) {
+		t.Errorf("replayed %q, recorded %q", replayed, body)
+	}
+}
+
+func TestReplayerNeedsARecording(t *testing.T) {
+	_, err := Replayer{Dir: "testdata/cassettes"}.Fetch(context.Background(), "https://example.com/unrecorded.xml")
+	if err == nil {
+		t.Error("replayed a feed that was never recorded")
+	}
+}
+
+func TestReplayerScrapesRealWorldFeeds(t *testing.T) {
+	sc, user := replayFeeds(t, wordpressURL, atomBlogURL, podcastURL)
+	ctx := context.Background()
+
+	for range 4 {
+		scrape(t, sc)
+	}
+
+	if n := len(postsFor(t, sc.DB, user)); n != 6 {
+		t.Errorf("got %d posts, want 6", n)
+	}
+
+	post := func(feedURL, guid string) database.Post {
+		t.Helper()
+
+		f, err := sc.DB.GetFeedByURL(ctx, feedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := sc.DB.GetPostByGUID(ctx, database.GetPostByGUIDParams{FeedID: f.ID, Guid: guid})
+		if err != nil {
+			t.Fatalf("%s has no post %s: %v", feedURL, guid, err)
+		}
+
+		return p
+	}
+	categories := func(p database.Post) []string {
+		t.Helper()
+
+		names, err := sc.DB.GetCategoriesForPost(ctx, p.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(names)
+
+		return names
+	}
+
+	wordpress, err := sc.DB.GetFeedByURL(ctx, wordpressURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wordpress.SiteUrl.String != "https://gardennotes.example.org" {
+		t.Errorf("got site url %q from the atom:link next to it", wordpress.SiteUrl.String)
+	}
+	if wordpress.Description.String != "Growing things, badly – and sometimes well" {
+		t.Errorf("got description %q", wordpress.Description.String)
+	}
+
+	pruning := post(wordpressURL, "https://gardennotes.example.org/?p=1482")
+	if pruning.Author.String != "Margaret Holt" {
+		t.Errorf("got author %q from dc:creator", pruning.Author.String)
+	}
+	if pruning.CommentsUrl.String != "https://gardennotes.example.org/2025/01/14/winter-pruning/#comments" {
+		t.Errorf("got comments url %q", pruning.CommentsUrl.String)
+	}
+	if !strings.Contains(pruning.Content.String, "apple-1024x768.jpg") {
+		t.Errorf("got content %q, want the content:encoded", pruning.Content.String)
+	}
+	if got := categories(pruning); !slices.Equal(got, []string{"Fruit trees", "Pruning"}) {
+		t.Errorf("got categories %q", got)
+	}
+	if seeds := post(wordpressURL, "https://gardennotes.example.org/?p=1475"); seeds.Title.String != "Seed catalogue season & a confession" {
+		t.Errorf("got title %q", seeds.Title.String)
+	}
+
+	pratt := post(atomBlogURL, "https://compilerdiaries.example.net/posts/pratt-error-recovery/")
+	if pratt.Url.String != "https://compilerdiaries.example.net/posts/pratt-error-recovery/" {
+		t.Errorf("got url %q", pratt.Url.String)
+	}
+	if pratt.Author.String != "Tomás Rivera" {
+		t.Errorf("got author %q", pratt.Author.String)
+	}
+	if got := categories(pratt); !slices.Equal(got, []string{"parsing", "rust"}) {
+		t.Errorf("got categories %q", got)
+	}
+	// an entry without <published> falls back to <updated>
+	secondPass := post(atomBlogURL, "https://compilerdiaries.example.net/posts/second-pass/")
+	if want := time.Date(2024, 12, 2, 9, 0, 0, 0, time.UTC); !secondPass.PublishedAt.Time.Equal(want) {
+		t.Errorf("got published %v, want %v", secondPass.PublishedAt.Time, want)
+	}
+
+	podcast, err := sc.DB.GetFeedByURL(ctx, podcastURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if podcast.ImageUrl.String != "https://cdn.example.fm/late-shift/artwork-3000.jpg" {
+		t.Errorf("got image %q from itunes:image", podcast.ImageUrl.String)
+	}
+
+	episode := post(podcastURL, "c1f7b3e2-9a4d-4e0b-8d55-3f1e2a6b9c42")
+	// itunes:title and itunes:author don't replace them
+	if episode.Title.String != "42: The Vending Machine Incident" {
+		t.Errorf("got title %q", episode.Title.String)
+	}
+	if episode.Author.String != "hello@lateshift.example.fm (Late Shift Media)" {
+		t.Errorf("got author %q", episode.Author.String)
+	}
+	media, err := sc.DB.GetEnclosuresForPost(ctx, episode.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// media:content repeats the enclosure, so there's only one
+	if len(media) != 1 {
+		t.Fatalf("got media %+v, want only the enclosure", media)
+	}
+	got := media[0]
+	if got.Url != "https://cdn.example.fm/late-shift/042.mp3?source=feed" ||
+		got.MediaType.String != "audio/mpeg" ||
+		got.Length.Int64 != 48213504 ||
+		got.Duration.String != "00:50:13" ||
+		got.Episode.String != "42" ||
+		got.ImageUrl.String != "https://cdn.example.fm/late-shift/042.jpg" ||
+		got.ThumbnailUrl.String != "https://cdn.example.fm/late-shift/042-thumb.jpg" {
+		t.Errorf("got media %+v", got)
+	}
+}
@@ -0,0 +1,22 @@
+package feed
+
+import "time"
+
+// Clock tells the scraper what time it is, so timestamps can be pinned down
+// when the output needs to be reproducible.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same time.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
@@ -2,72 +2,179 @@ package feed
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html"
-	"io"
-	"net/http"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/webhook"
 	"github.com/google/uuid"
 )
 
 type RSSFeed struct {
 	Channel struct {
 		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
+		Link        string    `xml:"-"`
+		Links       []rssLink `xml:"link"`
 		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Language    string    `xml:"language"`
+		// itunes:image has to come before image, which would otherwise
+		// match it too
+		ITunesImage ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		Image       struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		Item []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
-func (rf RSSFeed) Unescape() {
+// rssLink catches both the channel's own <link> and any <atom:link> elements
+// next to it, which would otherwise overwrite it.
+type rssLink struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+func (rf RSSFeed) ImageURL() string {
+	if rf.Channel.Image.URL != "" {
+		return rf.Channel.Image.URL
+	}
+
+	return rf.Channel.ITunesImage.Href
+}
+
+func (rf *RSSFeed) Unescape() {
 	rf.Channel.Title = html.UnescapeString(rf.Channel.Title)
 	rf.Channel.Description = html.UnescapeString(rf.Channel.Description)
 
 	for i := range rf.Channel.Item {
 		rf.Channel.Item[i].Title = html.UnescapeString(rf.Channel.Item[i].Title)
 		rf.Channel.Item[i].Description = html.UnescapeString(rf.Channel.Item[i].Description)
+		rf.Channel.Item[i].Author = html.UnescapeString(rf.Channel.Item[i].Author)
+		rf.Channel.Item[i].Creator = html.UnescapeString(rf.Channel.Item[i].Creator)
+		for j := range rf.Channel.Item[i].Categories {
+			rf.Channel.Item[i].Categories[j] = html.UnescapeString(rf.Channel.Item[i].Categories[j])
+		}
 	}
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	// elements from other namespaces that share a name with an RSS one have
+	// to come first, since the RSS fields would otherwise match them too
+	ITunesTitle   string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	ITunesAuthor  string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	MediaTitle    string `xml:"http://search.yahoo.com/mrss/ title"`
+	SlashComments string `xml:"http://purl.org/rss/1.0/modules/slash/ comments"`
+
+	Title          string   `xml:"title"`
+	Link           string   `xml:"link"`
+	Description    string   `xml:"description"`
+	PubDate        string   `xml:"pubDate"`
+	GUID           string   `xml:"guid"`
+	ContentEncoded string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author         string   `xml:"author"`
+	Creator        string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories     []string `xml:"category"`
+	Comments       string   `xml:"comments"`
+	DCDate         string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+
+	Enclosures      []RSSEnclosure   `xml:"enclosure"`
+	ITunesDuration  string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesEpisode   string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ITunesImage     ITunesImage      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	MediaContents   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaGroups     []MediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
-	if err != nil {
-		return nil, err
+// ItemAuthor prefers the RSS author element and falls back to dc:creator.
+func (ri RSSItem) ItemAuthor() string {
+	if ri.Author != "" {
+		return ri.Author
 	}
-	req.Header.Set("User-Agent", "gator")
 
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+	return ri.Creator
+}
+
+// Published prefers pubDate and falls back to dc:date.
+func (ri RSSItem) Published() string {
+	if ri.PubDate != "" {
+		return ri.PubDate
 	}
-	defer res.Body.Close()
 
-	data, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+	return ri.DCDate
+}
+
+// ID returns what identifies the item within its feed: the guid if it has
+// one, then the link, and failing both a hash of the item's content.
+func (ri RSSItem) ID() string {
+	if ri.GUID != "" {
+		return ri.GUID
+	}
+	if ri.Link != "" {
+		return ri.Link
+	}
+
+	sum := sha256.Sum256([]byte(ri.Title + "\x00" + ri.Description + "\x00" + ri.PubDate))
+	return hex.EncodeToString(sum[:])
+}
+
+// Scraper fetches feeds and saves their posts. Fetcher, Clock and Logger
+// default to DefaultFetcher, SystemClock and slog.Default() when left nil.
+// New posts are added to Webhooks, if it's set; otherwise no webhooks fire.
+type Scraper struct {
+	DB       database.Store
+	Fetcher  Fetcher
+	Clock    Clock
+	Logger   *slog.Logger
+	Webhooks *webhook.Queue
+}
+
+func (sc Scraper) now() time.Time {
+	clock := sc.Clock
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	return clock.Now()
+}
+
+func (sc Scraper) logger() *slog.Logger {
+	if sc.Logger == nil {
+		return slog.Default()
+	}
+
+	return sc.Logger
+}
+
+func (sc Scraper) fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+	fetcher := sc.Fetcher
+	if fetcher == nil {
+		fetcher = DefaultFetcher{}
 	}
 
-	newFeed := &RSSFeed{}
-	err = xml.Unmarshal(data, newFeed)
+	data, err := fetcher.Fetch(ctx, feedURL)
 	if err != nil {
 		return nil, err
 	}
 
-	return newFeed, nil
+	return parseFeed(data)
 }
 
-func ScrapeFeeds(db *database.Queries) error {
+// ScrapeFeeds fetches the feed that has gone longest without being fetched
+// and saves any new or edited posts from it. A feed that can't be fetched or
+// parsed is logged and skipped; only database errors are returned.
+func (sc Scraper) ScrapeFeeds() error {
+	db := sc.DB
+	start := time.Now()
+
 	nextFeed, err := db.GetNextFeedToFetch(context.Background())
 	if err != nil {
 		return err
@@ -76,7 +183,7 @@ func ScrapeFeeds(db *database.Queries) error {
 	err = db.MarkFeedFetched(
 		context.Background(),
 		database.MarkFeedFetchedParams{
-			LastFetchedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			LastFetchedAt: sql.NullTime{Time: sc.now(), Valid: true},
 			ID:            nextFeed.ID,
 		},
 	)
@@ -84,56 +191,296 @@ func ScrapeFeeds(db *database.Queries) error {
 		return err
 	}
 
-	rssFeed, err := fetchFeed(context.Background(), nextFeed.Url)
+	log := sc.logger().With(
+		slog.String("feed_id", nextFeed.ID.String()),
+		slog.String("feed_url", nextFeed.Url),
+	)
+
+	rssFeed, err := sc.fetchFeed(context.Background(), nextFeed.Url)
 	if err != nil {
-		return err
+		attrs := []any{
+			slog.Duration("duration", time.Since(start)),
+			slog.String("status", "failed"),
+			slog.Any("error", err),
+		}
+		var statusErr StatusError
+		if errors.As(err, &statusErr) {
+			attrs = append(attrs, slog.Int("http_status", statusErr.StatusCode))
+		}
+
+		log.Error("couldn't read feed", attrs...)
+		return nil
 	}
 	rssFeed.Unescape()
 
-	for _, item := range rssFeed.Channel.Item {
-		postTitle := sql.NullString{String: item.Title, Valid: true}
-		if postTitle.String == "" {
-			postTitle.Valid = false
-		}
+	err = db.UpdateFeedMetadata(
+		context.Background(),
+		database.UpdateFeedMetadataParams{
+			Title:       nullString(rssFeed.Channel.Title),
+			SiteUrl:     nullString(rssFeed.Channel.Link),
+			Description: nullString(rssFeed.Channel.Description),
+			ImageUrl:    nullString(rssFeed.ImageURL()),
+			Language:    nullString(rssFeed.Channel.Language),
+			ID:          nextFeed.ID,
+		},
+	)
+	if err != nil {
+		return err
+	}
 
-		postDescription := sql.NullString{String: item.Description, Valid: true}
-		if postDescription.String == "" {
-			postDescription.Valid = false
+	if nextFeed.LegacyGuids {
+		err = sc.adoptLegacyPosts(nextFeed, rssFeed.Channel.Item)
+		if err != nil {
+			return err
 		}
+	}
 
-		pubTime, err := parsePubDate(item.PubDate)
+	newPosts, updatedPosts, failedPosts := 0, 0, 0
+	for _, item := range rssFeed.Channel.Item {
+		pubTime, err := parsePubDate(item.Published())
 		if err != nil {
-			fmt.Printf("error saving post '%s' from feed '%s': %s\n", item.Title, nextFeed.Name, err)
+			log.Debug("post has no usable publish date", slog.String("title", item.Title), slog.Any("error", err))
 		}
 		postPubTime := sql.NullTime{Time: pubTime, Valid: false}
 		if err == nil {
 			postPubTime.Valid = true
 		}
 
-		post, err := db.CreatePost(
+		params := database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   sc.now(),
+			UpdatedAt:   sc.now(),
+			Title:       nullString(item.Title),
+			Url:         nullString(item.Link),
+			Description: nullString(item.Description),
+			PublishedAt: postPubTime,
+			FeedID:      nextFeed.ID,
+			Guid:        item.ID(),
+			Content:     nullString(item.ContentEncoded),
+			Author:      nullString(item.ItemAuthor()),
+			CommentsUrl: nullString(item.Comments),
+		}
+
+		post, err := db.CreatePost(context.Background(), params)
+		updated := false
+		if err == sql.ErrNoRows {
+			// we already have this item, but it may have been edited since
+			post, updated, err = sc.updatePost(nextFeed, params)
+		}
+		if err != nil {
+			log.Warn("couldn't save post", slog.String("title", item.Title), slog.Any("error", err))
+			failedPosts++
+			continue
+		}
+		if updated {
+			log.Debug("updated post", slog.String("post_id", post.ID.String()), slog.String("title", post.Title.String))
+			updatedPosts++
+		}
+
+		if post.ID != params.ID {
+			// the item's categories replace the ones saved last time, so
+			// categories dropped from it don't linger
+			err = db.DeletePostCategories(context.Background(), post.ID)
+			if err != nil {
+				log.Warn("couldn't replace post categories", slog.String("post_id", post.ID.String()), slog.Any("error", err))
+			}
+		}
+
+		for _, category := range item.Categories {
+			category = strings.TrimSpace(category)
+			if category == "" {
+				continue
+			}
+
+			err = db.CreatePostCategory(
+				context.Background(),
+				database.CreatePostCategoryParams{
+					PostID: post.ID,
+					Name:   category,
+				},
+			)
+			if err != nil {
+				log.Warn("couldn't save post category", slog.String("post_id", post.ID.String()), slog.String("category", category), slog.Any("error", err))
+			}
+		}
+
+		for _, enc := range item.Media() {
+			err = db.CreatePostEnclosure(
+				context.Background(),
+				database.CreatePostEnclosureParams{
+					ID:           uuid.New(),
+					CreatedAt:    sc.now(),
+					PostID:       post.ID,
+					Url:          enc.URL,
+					Source:       enc.Source,
+					MediaType:    nullString(enc.Type),
+					Length:       sql.NullInt64{Int64: enc.Length, Valid: enc.Length > 0},
+					Duration:     nullString(enc.Duration),
+					Episode:      nullString(enc.Episode),
+					ImageUrl:     nullString(enc.ImageURL),
+					ThumbnailUrl: nullString(enc.ThumbnailURL),
+				},
+			)
+			if err != nil {
+				log.Warn("couldn't save post media", slog.String("post_id", post.ID.String()), slog.String("media_url", enc.URL), slog.Any("error", err))
+			}
+		}
+
+		if post.ID != params.ID {
+			continue
+		}
+
+		log.Debug("saved post", slog.String("post_id", post.ID.String()), slog.String("title", post.Title.String))
+		newPosts++
+
+		if sc.Webhooks != nil {
+			sc.Webhooks.Add(nextFeed, post)
+		}
+	}
+
+	log.Info(
+		"fetched feed",
+		slog.Duration("duration", time.Since(start)),
+		slog.String("status", "ok"),
+		slog.Int("new_posts", newPosts),
+		slog.Int("updated_posts", updatedPosts),
+		slog.Int("failed_posts", failedPosts),
+	)
+
+	return nil
+}
+
+// adoptLegacyPosts gives the items' guids to posts saved before gator kept
+// track of guids, which got the post's url as its guid instead, so those
+// items are recognised rather than saved a second time. It only has to run
+// once for each feed the migration to guids flagged.
+func (sc Scraper) adoptLegacyPosts(f database.Feed, items []RSSItem) error {
+	byLink := make(map[string][]RSSItem)
+	for _, item := range items {
+		if item.Link != "" {
+			byLink[item.Link] = append(byLink[item.Link], item)
+		}
+	}
+
+	for _, item := range items {
+		guid := item.ID()
+		if item.Link == "" || guid == item.Link {
+			continue
+		}
+
+		// if an item without a guid has the same link, the post with it as
+		// a guid is that item's, and if several have it there's no telling
+		// whose it is
+		if len(byLink[item.Link]) != 1 {
+			continue
+		}
+
+		legacy, err := sc.DB.GetPostByGUID(
 			context.Background(),
-			database.CreatePostParams{
-				ID:          uuid.New(),
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
-				Title:       sql.NullString{String: item.Title, Valid: true},
-				Url:         item.Link,
-				Description: sql.NullString{String: item.Description, Valid: true},
-				PublishedAt: postPubTime,
-				FeedID:      nextFeed.ID,
+			database.GetPostByGUIDParams{
+				FeedID: f.ID,
+				Guid:   item.Link,
 			},
 		)
+		if err == sql.ErrNoRows {
+			continue
+		}
 		if err != nil {
-			if err.Error() != `pq: duplicate key value violates unique constraint "posts_url_key"` {
-				fmt.Println(err)
-			}
+			return err
+		}
+
+		// the item may already have been saved again under its guid
+		_, err = sc.DB.GetPostByGUID(
+			context.Background(),
+			database.GetPostByGUIDParams{
+				FeedID: f.ID,
+				Guid:   guid,
+			},
+		)
+		if err == nil {
 			continue
 		}
+		if err != sql.ErrNoRows {
+			return err
+		}
 
-		fmt.Printf("Saved post '%s' from feed '%s'\n", post.Title.String, nextFeed.Name)
+		err = sc.DB.UpdatePostGUID(
+			context.Background(),
+			database.UpdatePostGUIDParams{
+				Guid: guid,
+				ID:   legacy.ID,
+			},
+		)
+		if err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return sc.DB.ClearFeedLegacyGUIDs(context.Background(), f.ID)
+}
+
+// updatePost brings an already saved post in line with the feed's current
+// version of it, keeping the old title and description as a revision. It
+// reports whether anything changed.
+func (sc Scraper) updatePost(f database.Feed, params database.CreatePostParams) (database.Post, bool, error) {
+	db := sc.DB
+
+	existing, err := db.GetPostByGUID(
+		context.Background(),
+		database.GetPostByGUIDParams{
+			FeedID: f.ID,
+			Guid:   params.Guid,
+		},
+	)
+	if err != nil {
+		return database.Post{}, false, err
+	}
+
+	if existing.Title == params.Title && existing.Description == params.Description {
+		return existing, false, nil
+	}
+
+	post, err := db.UpdatePost(
+		context.Background(),
+		database.UpdatePostParams{
+			UpdatedAt:   sc.now(),
+			Title:       params.Title,
+			Url:         params.Url,
+			Description: params.Description,
+			PublishedAt: params.PublishedAt,
+			Content:     params.Content,
+			Author:      params.Author,
+			CommentsUrl: params.CommentsUrl,
+			ID:          existing.ID,
+		},
+	)
+	if err != nil {
+		return database.Post{}, false, err
+	}
+
+	// the old version is only kept once the new one is saved, so a failed
+	// update doesn't leave a revision behind for every retry
+	err = db.CreatePostRevision(
+		context.Background(),
+		database.CreatePostRevisionParams{
+			ID:          uuid.New(),
+			CreatedAt:   sc.now(),
+			PostID:      existing.ID,
+			Title:       existing.Title,
+			Url:         existing.Url,
+			Description: existing.Description,
+			PublishedAt: existing.PublishedAt,
+		},
+	)
+	if err != nil {
+		return database.Post{}, false, err
+	}
+
+	return post, true, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
 
 func parsePubDate(pubDate string) (time.Time, error) {
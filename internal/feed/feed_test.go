@@ -0,0 +1,357 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/memory"
+	"github.com/google/uuid"
+)
+
+const testFeedURL = "https://example.com/feed.xml"
+
+// fakeFetcher serves feeds from memory, by url.
+type fakeFetcher map[string]string
+
+func (f fakeFetcher) Fetch(ctx context.Context, feedURL string) ([]byte, error) {
+	body, ok := f[feedURL]
+	if !ok {
+		return nil, fmt.Errorf("no feed at %s", feedURL)
+	}
+
+	return []byte(body), nil
+}
+
+func rss(items ...string) string {
+	return `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Test Feed</title><link>https://example.com/</link>` +
+		strings.Join(items, "\n") +
+		`</channel></rss>`
+}
+
+// newTestScraper sets up a user following one feed, served by the returned
+// fetcher.
+func newTestScraper(t *testing.T) (Scraper, fakeFetcher, database.User, database.Feed) {
+	t.Helper()
+
+	db := memory.New()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	user, err := db.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := db.CreateFeed(ctx, database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "test",
+		Url:       testFeedURL,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		FeedID:    f.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher := fakeFetcher{}
+	sc := Scraper{
+		DB:      db,
+		Fetcher: fetcher,
+		Clock:   FixedClock(now),
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	return sc, fetcher, user, f
+}
+
+func postsFor(t *testing.T, db database.Store, user database.User) []database.GetPostsForUserRow {
+	t.Helper()
+
+	posts, err := db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
+		UserID: user.ID,
+		Limit:  100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return posts
+}
+
+func scrape(t *testing.T, sc Scraper) {
+	t.Helper()
+
+	err := sc.ScrapeFeeds()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScrapeFeedsSavesPosts(t *testing.T) {
+	sc, fetcher, user, _ := newTestScraper(t)
+	fetcher[testFeedURL] = rss(
+		`<item><title>First</title><link>https://example.com/1</link><guid>1</guid>
+<pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate><category>go</category>
+<enclosure url="https://example.com/1.mp3" length="3" type="audio/mpeg"/></item>`,
+		`<item><title>Second</title><link>https://example.com/2</link><guid>2</guid></item>`,
+	)
+
+	scrape(t, sc)
+
+	posts := postsFor(t, sc.DB, user)
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2", len(posts))
+	}
+
+	first, err := sc.DB.GetPostByGUID(context.Background(), database.GetPostByGUIDParams{FeedID: posts[0].FeedID, Guid: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Title.String != "First" || !first.PublishedAt.Valid {
+		t.Errorf("saved %+v", first)
+	}
+
+	categories, err := sc.DB.GetCategoriesForPost(context.Background(), first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 1 || categories[0] != "go" {
+		t.Errorf("got categories %q, want [go]", categories)
+	}
+
+	enclosures, err := sc.DB.GetEnclosuresForPost(context.Background(), first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enclosures) != 1 || enclosures[0].Url != "https://example.com/1.mp3" {
+		t.Errorf("got enclosures %+v", enclosures)
+	}
+}
+
+func TestScrapeFeedsReplacesTheCategoriesOfEditedPosts(t *testing.T) {
+	sc, fetcher, _, f := newTestScraper(t)
+	fetcher[testFeedURL] = rss(`<item><title>First</title><guid>1</guid><category>go</category><category>draft</category></item>`)
+	scrape(t, sc)
+
+	fetcher[testFeedURL] = rss(`<item><title>First</title><guid>1</guid><category>go</category><category>databases</category></item>`)
+	scrape(t, sc)
+
+	post, err := sc.DB.GetPostByGUID(context.Background(), database.GetPostByGUIDParams{FeedID: f.ID, Guid: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	categories, err := sc.DB.GetCategoriesForPost(context.Background(), post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(categories, []string{"databases", "go"}) {
+		t.Errorf("got categories %q, want [databases go]", categories)
+	}
+}
+
+func TestScrapeFeedsRecognisesItemsItHasSeen(t *testing.T) {
+	sc, fetcher, user, _ := newTestScraper(t)
+	fetcher[testFeedURL] = rss(
+		`<item><title>Has a guid</title><link>https://example.com/1</link><guid>1</guid></item>`,
+		`<item><title>Has a link</title><link>https://example.com/2</link></item>`,
+		`<item><title>Has neither</title><description>just text</description></item>`,
+		// same link as the first item, but a different guid makes it a different post
+		`<item><title>Shares a link</title><link>https://example.com/1</link><guid>3</guid></item>`,
+	)
+
+	scrape(t, sc)
+	scrape(t, sc)
+
+	posts := postsFor(t, sc.DB, user)
+	if len(posts) != 4 {
+		t.Fatalf("got %d posts after scraping twice, want 4", len(posts))
+	}
+	for _, post := range posts {
+		if post.Updated {
+			t.Errorf("post %q was marked as updated", post.Title.String)
+		}
+	}
+}
+
+func TestScrapeFeedsSkipsFeedsItCantFetch(t *testing.T) {
+	sc, _, user, f := newTestScraper(t)
+
+	scrape(t, sc)
+
+	if posts := postsFor(t, sc.DB, user); len(posts) != 0 {
+		t.Errorf("got %d posts from a feed that couldn't be fetched", len(posts))
+	}
+
+	fetched, err := sc.DB.GetFeed(context.Background(), f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fetched.LastFetchedAt.Valid {
+		t.Error("feed wasn't marked as fetched, so it would be retried straight away")
+	}
+}
+
+func TestScrapeFeedsRevisesEditedPosts(t *testing.T) {
+	sc, fetcher, user, _ := newTestScraper(t)
+	fetcher[testFeedURL] = rss(`<item><title>Tpyo</title><guid>1</guid></item>`)
+	scrape(t, sc)
+
+	fetcher[testFeedURL] = rss(`<item><title>Typo</title><guid>1</guid></item>`)
+	scrape(t, sc)
+
+	posts := postsFor(t, sc.DB, user)
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	if posts[0].Title.String != "Typo" {
+		t.Errorf("got title %q, want the edited one", posts[0].Title.String)
+	}
+	if !posts[0].Updated {
+		t.Error("edited post has no revision")
+	}
+}
+
+// legacyFeeds is a store whose feeds are all flagged as having posts from
+// before guids were tracked, until they're cleared.
+type legacyFeeds struct {
+	*memory.Store
+	cleared map[uuid.UUID]bool
+}
+
+func (s legacyFeeds) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	f, err := s.Store.GetNextFeedToFetch(ctx)
+	f.LegacyGuids = !s.cleared[f.ID]
+	return f, err
+}
+
+func (s legacyFeeds) ClearFeedLegacyGUIDs(ctx context.Context, id uuid.UUID) error {
+	s.cleared[id] = true
+	return s.Store.ClearFeedLegacyGUIDs(ctx, id)
+}
+
+// addLegacyPost saves a post the way gator did before guids were tracked,
+// with its url as its guid.
+func addLegacyPost(t *testing.T, db database.Store, f database.Feed, title, url string) {
+	t.Helper()
+
+	_, err := db.CreatePost(context.Background(), database.CreatePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Title:     sql.NullString{String: title, Valid: true},
+		Url:       sql.NullString{String: url, Valid: true},
+		FeedID:    f.ID,
+		Guid:      url,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScrapeFeedsAdoptsPostsSavedBeforeGUIDs(t *testing.T) {
+	sc, fetcher, user, f := newTestScraper(t)
+	db := legacyFeeds{Store: sc.DB.(*memory.Store), cleared: make(map[uuid.UUID]bool)}
+	sc.DB = db
+
+	addLegacyPost(t, db, f, "Old", "https://example.com/1")
+	fetcher[testFeedURL] = rss(`<item><title>Old</title><link>https://example.com/1</link><guid>1</guid></item>`)
+	scrape(t, sc)
+	scrape(t, sc)
+
+	posts := postsFor(t, sc.DB, user)
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want the old one only", len(posts))
+	}
+	if posts[0].Guid != "1" || posts[0].Updated {
+		t.Errorf("old post has guid %q and updated %v, want 1 and unchanged", posts[0].Guid, posts[0].Updated)
+	}
+	if !db.cleared[f.ID] {
+		t.Error("feed is still flagged after adopting its posts")
+	}
+}
+
+func TestScrapeFeedsLeavesLegacyPostsOfItemsWithoutGUIDs(t *testing.T) {
+	sc, fetcher, user, f := newTestScraper(t)
+	sc.DB = legacyFeeds{Store: sc.DB.(*memory.Store), cleared: make(map[uuid.UUID]bool)}
+
+	// the old post belongs to the item without a guid, which is still
+	// identified by its link, not to the one with a guid sharing the link
+	addLegacyPost(t, sc.DB, f, "No guid", "https://example.com/1")
+	fetcher[testFeedURL] = rss(
+		`<item><title>No guid</title><link>https://example.com/1</link></item>`,
+		`<item><title>Has a guid</title><link>https://example.com/1</link><guid>2</guid></item>`,
+	)
+	scrape(t, sc)
+
+	posts := postsFor(t, sc.DB, user)
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2", len(posts))
+	}
+	for _, post := range posts {
+		if post.Updated {
+			t.Errorf("post %q was revised", post.Title.String)
+		}
+	}
+
+	old, err := sc.DB.GetPostByGUID(context.Background(), database.GetPostByGUIDParams{FeedID: f.ID, Guid: "https://example.com/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Title.String != "No guid" {
+		t.Errorf("the old post was given to %q", old.Title.String)
+	}
+}
+
+// failingUpdates is a store that can't update posts.
+type failingUpdates struct {
+	*memory.Store
+}
+
+func (failingUpdates) UpdatePost(ctx context.Context, arg database.UpdatePostParams) (database.Post, error) {
+	return database.Post{}, errors.New("connection reset")
+}
+
+func TestScrapeFeedsKeepsNoRevisionOfFailedUpdates(t *testing.T) {
+	sc, fetcher, user, _ := newTestScraper(t)
+	fetcher[testFeedURL] = rss(`<item><title>Tpyo</title><guid>1</guid></item>`)
+	scrape(t, sc)
+
+	sc.DB = failingUpdates{sc.DB.(*memory.Store)}
+	fetcher[testFeedURL] = rss(`<item><title>Typo</title><guid>1</guid></item>`)
+	scrape(t, sc)
+
+	posts := postsFor(t, sc.DB, user)
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	if posts[0].Updated {
+		t.Error("post has a revision even though it couldn't be updated")
+	}
+}
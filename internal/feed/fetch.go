@@ -0,0 +1,100 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// defaultClient gives up on feeds that take too long to answer, so one slow server
+// can't hold up a whole scrape.
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Fetcher gets the raw contents of the feed at feedURL.
+type Fetcher interface {
+	Fetch(ctx context.Context, feedURL string) ([]byte, error)
+}
+
+// HTTPFetcher fetches feeds over the network. A nil Client means one that
+// times out after 30 seconds.
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+func (f HTTPFetcher) Fetch(ctx context.Context, feedURL string) ([]byte, error) {
+	client := f.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "gator")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, StatusError{URL: feedURL, StatusCode: res.StatusCode, Status: res.Status}
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// StatusError is returned by HTTPFetcher when the server answers with
+// anything other than a 2xx status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("fetching %s failed with status %s", e.URL, e.Status)
+}
+
+// FileFetcher reads feeds from file:// urls.
+type FileFetcher struct{}
+
+func (FileFetcher) Fetch(ctx context.Context, feedURL string) ([]byte, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "file" {
+		return nil, fmt.Errorf("%s is not a file:// url", feedURL)
+	}
+
+	// file:relative/path.xml has no host or path, only the opaque part
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+
+	return os.ReadFile(path)
+}
+
+// DefaultFetcher reads file:// urls from disk and fetches everything else
+// over HTTP.
+type DefaultFetcher struct {
+	HTTP HTTPFetcher
+	File FileFetcher
+}
+
+func (f DefaultFetcher) Fetch(ctx context.Context, feedURL string) ([]byte, error) {
+	u, err := url.Parse(feedURL)
+	if err == nil && u.Scheme == "file" {
+		return f.File.Fetch(ctx, feedURL)
+	}
+
+	return f.HTTP.Fetch(ctx, feedURL)
+}
@@ -0,0 +1,132 @@
+package feed
+
+import (
+	"strconv"
+)
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type MediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+type MediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type MediaGroup struct {
+	Contents   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// Enclosure is a single media file attached to an item, with whatever
+// podcast and Media RSS details came with it.
+type Enclosure struct {
+	URL          string
+	Source       string
+	Type         string
+	Length       int64
+	Duration     string
+	Episode      string
+	ImageURL     string
+	ThumbnailURL string
+}
+
+// Media collects the item's enclosures and Media RSS content into one list,
+// skipping repeats of the same url. An item with nothing but a thumbnail
+// gets the thumbnail itself, so it isn't lost.
+func (ri RSSItem) Media() []Enclosure {
+	contents := ri.MediaContents
+	thumbnails := ri.MediaThumbnails
+	for _, group := range ri.MediaGroups {
+		contents = append(contents, group.Contents...)
+		thumbnails = append(thumbnails, group.Thumbnails...)
+	}
+
+	thumbnail := ""
+	if len(thumbnails) != 0 {
+		thumbnail = thumbnails[0].URL
+	}
+
+	media := make([]Enclosure, 0)
+	seen := make(map[string]bool)
+
+	for _, enc := range ri.Enclosures {
+		if enc.URL == "" || seen[enc.URL] {
+			continue
+		}
+		seen[enc.URL] = true
+
+		media = append(media, Enclosure{
+			URL:          enc.URL,
+			Source:       "enclosure",
+			Type:         enc.Type,
+			Length:       parseLength(enc.Length),
+			Duration:     ri.ITunesDuration,
+			Episode:      ri.ITunesEpisode,
+			ImageURL:     ri.ITunesImage.Href,
+			ThumbnailURL: thumbnail,
+		})
+	}
+
+	for _, content := range contents {
+		if content.URL == "" || seen[content.URL] {
+			continue
+		}
+		seen[content.URL] = true
+
+		mediaType := content.Type
+		if mediaType == "" {
+			mediaType = content.Medium
+		}
+		duration := content.Duration
+		if duration == "" {
+			duration = ri.ITunesDuration
+		}
+
+		media = append(media, Enclosure{
+			URL:          content.URL,
+			Source:       "media:content",
+			Type:         mediaType,
+			Length:       parseLength(content.FileSize),
+			Duration:     duration,
+			Episode:      ri.ITunesEpisode,
+			ImageURL:     ri.ITunesImage.Href,
+			ThumbnailURL: thumbnail,
+		})
+	}
+
+	if len(media) == 0 && thumbnail != "" {
+		media = append(media, Enclosure{
+			URL:          thumbnail,
+			Source:       "media:thumbnail",
+			Type:         "image",
+			Episode:      ri.ITunesEpisode,
+			ImageURL:     ri.ITunesImage.Href,
+			ThumbnailURL: thumbnail,
+		})
+	}
+
+	return media
+}
+
+func parseLength(length string) int64 {
+	n, err := strconv.ParseInt(length, 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+
+	return n
+}
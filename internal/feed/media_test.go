@@ -0,0 +1,52 @@
+package feed
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMedia(t *testing.T) {
+	const ns = `xmlns:media="http://search.yahoo.com/mrss/" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"`
+
+	for _, tc := range []struct {
+		name string
+		item string
+		want []Enclosure
+	}{
+		{
+			name: "enclosure",
+			item: `<item ` + ns + `><enclosure url="https://example.com/1.mp3" length="3" type="audio/mpeg"/>
+<itunes:duration>1:02</itunes:duration><media:thumbnail url="https://example.com/1.jpg"/></item>`,
+			want: []Enclosure{{URL: "https://example.com/1.mp3", Source: "enclosure", Type: "audio/mpeg", Length: 3, Duration: "1:02", ThumbnailURL: "https://example.com/1.jpg"}},
+		},
+		{
+			name: "media content repeating the enclosure",
+			item: `<item ` + ns + `><enclosure url="https://example.com/1.mp4" type="video/mp4"/>
+<media:group><media:content url="https://example.com/1.mp4"/><media:content url="https://example.com/1.webm" medium="video" fileSize="-1"/></media:group></item>`,
+			want: []Enclosure{
+				{URL: "https://example.com/1.mp4", Source: "enclosure", Type: "video/mp4"},
+				{URL: "https://example.com/1.webm", Source: "media:content", Type: "video"},
+			},
+		},
+		{
+			name: "only a thumbnail",
+			item: `<item ` + ns + `><media:thumbnail url="https://example.com/1.jpg"/><media:thumbnail url="https://example.com/2.jpg"/></item>`,
+			want: []Enclosure{{URL: "https://example.com/1.jpg", Source: "media:thumbnail", Type: "image", ThumbnailURL: "https://example.com/1.jpg"}},
+		},
+		{
+			name: "nothing",
+			item: `<item><title>Text only</title></item>`,
+			want: []Enclosure{},
+		},
+	} {
+		f, err := parseFeed([]byte(rss(tc.item)))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		got := f.Channel.Item[0].Media()
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
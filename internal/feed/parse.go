@@ -0,0 +1,144 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+const atomNS = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Icon     string      `xml:"icon"`
+	Logo     string      `xml:"logo"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Summary    string         `xml:"summary"`
+	Content    string         `xml:"content"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomAuthor   `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// parseFeed reads either an RSS or an Atom document. Atom feeds are mapped
+// onto the RSS structures so the rest of gator only deals with one shape.
+func parseFeed(data []byte) (*RSSFeed, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	err := unmarshal(data, &root)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.XMLName.Local != "feed" {
+		newFeed := &RSSFeed{}
+		err = unmarshal(data, newFeed)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, link := range newFeed.Channel.Links {
+			if link.XMLName.Space != atomNS && strings.TrimSpace(link.Value) != "" {
+				newFeed.Channel.Link = strings.TrimSpace(link.Value)
+				break
+			}
+		}
+
+		return newFeed, nil
+	}
+
+	atom := atomFeed{}
+	err = unmarshal(data, &atom)
+	if err != nil {
+		return nil, err
+	}
+
+	newFeed := &RSSFeed{}
+	newFeed.Channel.Title = atom.Title
+	newFeed.Channel.Link = alternateLink(atom.Links)
+	newFeed.Channel.Description = atom.Subtitle
+	newFeed.Channel.Language = atom.Lang
+	newFeed.Channel.Image.URL = atom.Logo
+	if newFeed.Channel.Image.URL == "" {
+		newFeed.Channel.Image.URL = atom.Icon
+	}
+
+	for _, entry := range atom.Entries {
+		item := RSSItem{
+			Title:          entry.Title,
+			Link:           alternateLink(entry.Links),
+			Description:    entry.Summary,
+			PubDate:        entry.Published,
+			GUID:           entry.ID,
+			ContentEncoded: entry.Content,
+		}
+		if item.Description == "" {
+			item.Description = entry.Content
+		}
+		if len(entry.Authors) != 0 {
+			item.Author = entry.Authors[0].Name
+		}
+		for _, category := range entry.Categories {
+			item.Categories = append(item.Categories, category.Term)
+		}
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" {
+				item.Enclosures = append(item.Enclosures, RSSEnclosure{URL: link.Href, Length: link.Length, Type: link.Type})
+			}
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.Updated
+		}
+
+		newFeed.Channel.Item = append(newFeed.Channel.Item, item)
+	}
+
+	return newFeed, nil
+}
+
+// unmarshal is xml.Unmarshal for documents in any encoding, like the
+// ISO-8859-1 and windows-1252 feeds older blogs still serve.
+func unmarshal(data []byte, v any) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charset.NewReaderLabel
+
+	return dec.Decode(v)
+}
+
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+
+	return ""
+}
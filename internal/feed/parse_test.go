@@ -0,0 +1,30 @@
+package feed
+
+import "testing"
+
+func TestParseFeedReadsOtherEncodings(t *testing.T) {
+	for _, tc := range []struct {
+		encoding string
+		title    string
+		want     string
+	}{
+		{"ISO-8859-1", "Caf\xe9 cr\xe8me", "Café crème"},
+		{"windows-1252", "\x93Quoted\x94 \x80 5", "“Quoted” € 5"},
+	} {
+		data := `<?xml version="1.0" encoding="` + tc.encoding + `"?>
+<rss version="2.0"><channel><title>` + tc.title + `</title>
+<item><title>` + tc.title + `</title></item></channel></rss>`
+
+		f, err := parseFeed([]byte(data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.encoding, err)
+			continue
+		}
+		if f.Channel.Title != tc.want {
+			t.Errorf("%s: got title %q, want %q", tc.encoding, f.Channel.Title, tc.want)
+		}
+		if len(f.Channel.Item) != 1 || f.Channel.Item[0].Title != tc.want {
+			t.Errorf("%s: got items %+v, want one titled %q", tc.encoding, f.Channel.Item, tc.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	ActionHide = "hide"
+	ActionOnly = "only"
+)
+
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldAny         = "any"
+)
+
+type rule struct {
+	filter database.Filter
+	re     *regexp.Regexp
+}
+
+type Set struct {
+	rules []rule
+}
+
+func Compile(filters []database.Filter) (Set, error) {
+	set := Set{rules: make([]rule, 0, len(filters))}
+
+	for _, f := range filters {
+		re, err := ParsePattern(f.Pattern)
+		if err != nil {
+			return Set{}, fmt.Errorf("filter %v has an invalid pattern: %w", f.ID, err)
+		}
+
+		set.rules = append(set.rules, rule{filter: f, re: re})
+	}
+
+	return set, nil
+}
+
+// ParsePattern accepts either a plain Go regular expression or the
+// /pattern/flags form, e.g. /sponsored/i. Only text ending in a slash and
+// some of the flags imsU counts as the second form, so patterns like
+// /blog/2024 are matched as they are.
+func ParsePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") {
+		end := strings.LastIndex(pattern, "/")
+		flags := pattern[end+1:]
+
+		if end > 0 && strings.Trim(flags, "imsU") == "" {
+			body := pattern[1:end]
+			if flags != "" {
+				body = fmt.Sprintf("(?%s)%s", flags, body)
+			}
+
+			return regexp.Compile(body)
+		}
+	}
+
+	return regexp.Compile(pattern)
+}
+
+func ValidAction(action string) bool {
+	return action == ActionHide || action == ActionOnly
+}
+
+func ValidField(field string) bool {
+	return field == FieldTitle || field == FieldDescription || field == FieldAny
+}
+
+// Allows reports whether a post passes every rule that applies to its feed.
+// When it doesn't, the rule responsible is returned as well.
+func (s Set) Allows(feedID uuid.UUID, title, description string) (bool, *database.Filter) {
+	var firstOnly *database.Filter
+	onlyMatched := false
+
+	for i := range s.rules {
+		r := &s.rules[i]
+		if r.filter.FeedID.Valid && r.filter.FeedID.UUID != feedID {
+			continue
+		}
+
+		matched := r.matches(title, description)
+
+		switch r.filter.Action {
+		case ActionHide:
+			if matched {
+				return false, &r.filter
+			}
+		case ActionOnly:
+			if firstOnly == nil {
+				firstOnly = &r.filter
+			}
+			if matched {
+				onlyMatched = true
+			}
+		}
+	}
+
+	if firstOnly != nil && !onlyMatched {
+		return false, firstOnly
+	}
+
+	return true, nil
+}
+
+func (r rule) matches(title, description string) bool {
+	switch r.filter.Field {
+	case FieldTitle:
+		return r.re.MatchString(title)
+	case FieldDescription:
+		return r.re.MatchString(description)
+	default:
+		return r.re.MatchString(title) || r.re.MatchString(description)
+	}
+}
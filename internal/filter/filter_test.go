@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestParsePattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		matches []string
+		misses  []string
+		invalid bool
+	}{
+		{pattern: "golang", matches: []string{"learn golang"}, misses: []string{"Golang"}},
+		{pattern: "/sponsored/i", matches: []string{"SPONSORED post"}, misses: []string{"sponsor"}},
+		{pattern: "/^go$/", matches: []string{"go"}, misses: []string{"/go/"}},
+		{pattern: "/a.b/s", matches: []string{"a\nb"}},
+		{pattern: "/a.*b/U", matches: []string{"ab"}},
+		// text after the last slash that isn't flags makes it a plain pattern
+		{pattern: "/blog/2024", matches: []string{"https://example.com/blog/2024/01"}, misses: []string{"blog"}},
+		{pattern: "/usr/bin", matches: []string{"/usr/bin/gator"}, misses: []string{"usr"}},
+		{pattern: "/", matches: []string{"a/b"}},
+		{pattern: "/(/", invalid: true},
+		{pattern: "[", invalid: true},
+	} {
+		re, err := ParsePattern(tc.pattern)
+		if tc.invalid {
+			if err == nil {
+				t.Errorf("ParsePattern(%q) accepted an invalid pattern", tc.pattern)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePattern(%q): %v", tc.pattern, err)
+			continue
+		}
+
+		for _, s := range tc.matches {
+			if !re.MatchString(s) {
+				t.Errorf("%q doesn't match %q", tc.pattern, s)
+			}
+		}
+		for _, s := range tc.misses {
+			if re.MatchString(s) {
+				t.Errorf("%q matches %q", tc.pattern, s)
+			}
+		}
+	}
+}
+
+func TestAllows(t *testing.T) {
+	blog, news := uuid.New(), uuid.New()
+	rule := func(action, field, pattern string, feedID uuid.UUID) database.Filter {
+		return database.Filter{
+			ID:      uuid.New(),
+			FeedID:  uuid.NullUUID{UUID: feedID, Valid: feedID != uuid.Nil},
+			Action:  action,
+			Field:   field,
+			Pattern: pattern,
+		}
+	}
+
+	hideAds := rule(ActionHide, FieldTitle, "/sponsored/i", uuid.Nil)
+	onlyGo := rule(ActionOnly, FieldAny, "golang", news)
+	onlyRust := rule(ActionOnly, FieldDescription, "rust", news)
+
+	set, err := Compile([]database.Filter{hideAds, onlyGo, onlyRust})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		feedID      uuid.UUID
+		title       string
+		description string
+		allowed     bool
+		by          *database.Filter
+	}{
+		{"nothing applies", blog, "Hello", "", true, nil},
+		{"hide applies to every feed", blog, "Sponsored: a thing", "", false, &hideAds},
+		{"hide only checks its field", blog, "Hello", "sponsored", true, nil},
+		{"hide beats only", news, "Sponsored golang", "", false, &hideAds},
+		{"only rules are for their feed", news, "Hello", "", false, &onlyGo},
+		{"any only rule will do", news, "Hello", "all about rust", true, nil},
+		{"any field matches any", news, "", "golang", true, nil},
+		{"field is respected by only", news, "rust", "", false, &onlyGo},
+	} {
+		allowed, by := set.Allows(tc.feedID, tc.title, tc.description)
+		if allowed != tc.allowed {
+			t.Errorf("%s: allowed = %v, want %v", tc.name, allowed, tc.allowed)
+		}
+		if (by == nil) != (tc.by == nil) || by != nil && by.ID != tc.by.ID {
+			t.Errorf("%s: blamed %+v, want %+v", tc.name, by, tc.by)
+		}
+	}
+}
+
+func TestCompileRejectsBadPatterns(t *testing.T) {
+	_, err := Compile([]database.Filter{{ID: uuid.New(), Action: ActionHide, Field: FieldAny, Pattern: "("}})
+	if err == nil {
+		t.Error("compiled a filter with an invalid pattern")
+	}
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CountDownloads(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.downloads)), nil
+}
+
+func (s *Store) CreateDownload(ctx context.Context, arg database.CreateDownloadParams) (database.Download, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.downloads, func(d database.Download) bool { return d.ID == arg.ID }); ok {
+		return database.Download{}, conflict("downloads_pkey")
+	}
+	if _, ok := find(s.downloads, func(d database.Download) bool { return d.Path == arg.Path }); ok {
+		return database.Download{}, conflict("downloads_path_key")
+	}
+	if _, ok := find(s.postEnclosures, func(e database.PostEnclosure) bool { return e.ID == arg.EnclosureID }); !ok {
+		return database.Download{}, missing("fk_enclosure_id")
+	}
+
+	download := database.Download{
+		ID:          arg.ID,
+		CreatedAt:   timestamp(arg.CreatedAt),
+		EnclosureID: arg.EnclosureID,
+		Path:        arg.Path,
+		Size:        arg.Size,
+		Sha256:      arg.Sha256,
+	}
+	s.downloads = append(s.downloads, download)
+
+	return download, nil
+}
+
+func (s *Store) GetDownloadByPath(ctx context.Context, path string) (database.Download, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.downloads, func(d database.Download) bool { return d.Path == path })
+	if !ok {
+		return database.Download{}, sql.ErrNoRows
+	}
+
+	return s.downloads[i], nil
+}
+
+func (s *Store) GetDownloadsForEnclosure(ctx context.Context, enclosureID uuid.UUID) ([]database.Download, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.Download
+	for _, d := range s.downloads {
+		if d.EnclosureID == enclosureID {
+			items = append(items, d)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Download) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return items, nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CountPostEnclosures(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.postEnclosures)), nil
+}
+
+func (s *Store) CreatePostEnclosure(ctx context.Context, arg database.CreatePostEnclosureParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// ON CONFLICT (post_id, url) DO NOTHING
+	if _, ok := find(s.postEnclosures, func(e database.PostEnclosure) bool { return e.PostID == arg.PostID && e.Url == arg.Url }); ok {
+		return nil
+	}
+	if _, ok := find(s.postEnclosures, func(e database.PostEnclosure) bool { return e.ID == arg.ID }); ok {
+		return conflict("post_enclosures_pkey")
+	}
+	if !s.hasPost(arg.PostID) {
+		return missing("fk_post_id")
+	}
+
+	s.postEnclosures = append(s.postEnclosures, database.PostEnclosure{
+		ID:           arg.ID,
+		CreatedAt:    timestamp(arg.CreatedAt),
+		PostID:       arg.PostID,
+		Url:          arg.Url,
+		Source:       arg.Source,
+		MediaType:    arg.MediaType,
+		Length:       arg.Length,
+		Duration:     arg.Duration,
+		Episode:      arg.Episode,
+		ImageUrl:     arg.ImageUrl,
+		ThumbnailUrl: arg.ThumbnailUrl,
+	})
+
+	return nil
+}
+
+func (s *Store) GetEnclosuresForPost(ctx context.Context, postID uuid.UUID) ([]database.PostEnclosure, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.PostEnclosure
+	for _, e := range s.postEnclosures {
+		if e.PostID == postID {
+			items = append(items, e)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.PostEnclosure) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return items, nil
+}
+
+func (s *Store) GetEnclosuresForPostsPage(ctx context.Context, arg database.GetEnclosuresForPostsPageParams) ([]database.PostEnclosure, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.PostEnclosure
+	for _, p := range s.postsPage(arg.UserID, int(arg.Limit), int(arg.Offset)) {
+		for _, e := range s.postEnclosures {
+			if e.PostID == p.ID {
+				items = append(items, e)
+			}
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.PostEnclosure) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return items, nil
+}
+
+func (s *Store) GetEnclosuresForUser(ctx context.Context, arg database.GetEnclosuresForUserParams) ([]database.GetEnclosuresForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type row struct {
+		database.GetEnclosuresForUserRow
+		publishedAt sql.NullTime
+	}
+
+	var rows []row
+	for _, e := range s.postEnclosures {
+		p, ok := find(s.posts, func(p database.Post) bool { return p.ID == e.PostID })
+		if !ok {
+			continue
+		}
+		post := s.posts[p]
+		if !s.follows(arg.UserID, post.FeedID) || post.CreatedAt.Before(arg.CreatedAt) {
+			continue
+		}
+		f, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == post.FeedID })
+		if !ok {
+			continue
+		}
+
+		rows = append(rows, row{
+			GetEnclosuresForUserRow: database.GetEnclosuresForUserRow{
+				ID:           e.ID,
+				CreatedAt:    e.CreatedAt,
+				PostID:       e.PostID,
+				Url:          e.Url,
+				Source:       e.Source,
+				MediaType:    e.MediaType,
+				Length:       e.Length,
+				Duration:     e.Duration,
+				Episode:      e.Episode,
+				ImageUrl:     e.ImageUrl,
+				ThumbnailUrl: e.ThumbnailUrl,
+				PostTitle:    post.Title,
+				FeedName:     s.feeds[f].Name,
+				FeedUrl:      s.feeds[f].Url,
+			},
+			publishedAt: post.PublishedAt,
+		})
+	}
+	slices.SortStableFunc(rows, func(a, b row) int {
+		return compareNullTime(a.publishedAt, b.publishedAt)
+	})
+
+	var items []database.GetEnclosuresForUserRow
+	for _, r := range rows {
+		items = append(items, r.GetEnclosuresForUserRow)
+	}
+
+	return items, nil
+}
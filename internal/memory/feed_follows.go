@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CountFeedFollows(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.feedFollows)), nil
+}
+
+func (s *Store) CountFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return count(s.feedFollows, func(ff database.FeedFollow) bool { return ff.UserID == userID }), nil
+}
+
+func (s *Store) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.feedFollows, func(ff database.FeedFollow) bool { return ff.ID == arg.ID }); ok {
+		return database.CreateFeedFollowRow{}, conflict("feed_follows_pkey")
+	}
+	if s.follows(arg.UserID, arg.FeedID) {
+		return database.CreateFeedFollowRow{}, conflict("feed_follows_user_id_feed_id_key")
+	}
+
+	u, ok := find(s.users, func(u database.User) bool { return u.ID == arg.UserID })
+	if !ok {
+		return database.CreateFeedFollowRow{}, missing("fk_user_id")
+	}
+	f, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == arg.FeedID })
+	if !ok {
+		return database.CreateFeedFollowRow{}, missing("fk_feed_id")
+	}
+
+	s.feedFollows = append(s.feedFollows, database.FeedFollow{
+		ID:        arg.ID,
+		CreatedAt: timestamp(arg.CreatedAt),
+		UpdatedAt: timestamp(arg.UpdatedAt),
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+	})
+
+	return database.CreateFeedFollowRow{
+		ID:        arg.ID,
+		CreatedAt: timestamp(arg.CreatedAt),
+		UpdatedAt: timestamp(arg.UpdatedAt),
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		FeedName:  s.feeds[f].Name,
+		UserName:  s.users[u].Name,
+	}, nil
+}
+
+func (s *Store) GetFeedFollowsForUser(ctx context.Context, name string) ([]database.GetFeedFollowsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.GetFeedFollowsForUserRow
+	for _, ff := range s.feedFollows {
+		u, ok := find(s.users, func(u database.User) bool { return u.ID == ff.UserID })
+		if !ok || s.users[u].Name != name {
+			continue
+		}
+		f, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == ff.FeedID })
+		if !ok {
+			continue
+		}
+
+		items = append(items, database.GetFeedFollowsForUserRow{
+			ID:        ff.ID,
+			CreatedAt: ff.CreatedAt,
+			UpdatedAt: ff.UpdatedAt,
+			UserID:    ff.UserID,
+			FeedID:    ff.FeedID,
+			FeedName:  s.feeds[f].Name,
+			UserName:  s.users[u].Name,
+		})
+	}
+
+	return items, nil
+}
+
+func (s *Store) UnfollowUserFromFeed(ctx context.Context, arg database.UnfollowUserFromFeedParams) (uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := find(s.feeds, func(f database.Feed) bool { return f.Url == arg.Url })
+	if !ok {
+		return uuid.UUID{}, sql.ErrNoRows
+	}
+	feedID := s.feeds[f].ID
+
+	var removed []database.FeedFollow
+	s.feedFollows, removed = remove(s.feedFollows, func(ff database.FeedFollow) bool {
+		return ff.FeedID == feedID && ff.UserID == arg.UserID
+	})
+	if len(removed) == 0 {
+		return uuid.UUID{}, sql.ErrNoRows
+	}
+
+	return feedID, nil
+}
@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) ClearFeedLegacyGUIDs(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == id })
+	if !ok {
+		return nil
+	}
+
+	s.feeds[i].LegacyGuids = false
+
+	return nil
+}
+
+func (s *Store) CountFeedFollowers(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return count(s.feedFollows, func(ff database.FeedFollow) bool { return ff.FeedID == feedID }), nil
+}
+
+func (s *Store) CountFeeds(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.feeds)), nil
+}
+
+func (s *Store) CountFeedsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return count(s.feeds, func(f database.Feed) bool { return f.UserID == userID }), nil
+}
+
+func (s *Store) CountPostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return count(s.posts, func(p database.Post) bool { return p.FeedID == feedID }), nil
+}
+
+func (s *Store) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == arg.ID }); ok {
+		return database.Feed{}, conflict("feeds_pkey")
+	}
+	if _, ok := find(s.feeds, func(f database.Feed) bool { return f.Url == arg.Url }); ok {
+		return database.Feed{}, conflict("feeds_url_key")
+	}
+	if !s.hasUser(arg.UserID) {
+		return database.Feed{}, missing("fk_user_id")
+	}
+
+	feed := database.Feed{
+		ID:        arg.ID,
+		CreatedAt: timestamp(arg.CreatedAt),
+		UpdatedAt: timestamp(arg.UpdatedAt),
+		Name:      arg.Name,
+		Url:       arg.Url,
+		UserID:    arg.UserID,
+	}
+	s.feeds = append(s.feeds, feed)
+
+	return feed, nil
+}
+
+func (s *Store) DeleteAllFeeds(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteFeeds(func(database.Feed) bool { return true })
+
+	return nil
+}
+
+func (s *Store) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteFeeds(func(f database.Feed) bool { return f.ID == id })
+
+	return nil
+}
+
+func (s *Store) GetFeed(ctx context.Context, id uuid.UUID) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == id })
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+
+	return s.feeds[i], nil
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, url string) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.feeds, func(f database.Feed) bool { return f.Url == url })
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+
+	return s.feeds[i], nil
+}
+
+func (s *Store) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.Feed
+	items = append(items, s.feeds...)
+
+	return items, nil
+}
+
+func (s *Store) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.feeds) == 0 {
+		return database.Feed{}, sql.ErrNoRows
+	}
+
+	// feeds that have never been fetched go first
+	next := slices.MinFunc(s.feeds, func(a, b database.Feed) int {
+		switch {
+		case !a.LastFetchedAt.Valid && b.LastFetchedAt.Valid:
+			return -1
+		case a.LastFetchedAt.Valid && !b.LastFetchedAt.Valid:
+			return 1
+		}
+		return compareNullTime(a.LastFetchedAt, b.LastFetchedAt)
+	})
+
+	return next, nil
+}
+
+func (s *Store) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == arg.ID })
+	if !ok {
+		return nil
+	}
+
+	s.feeds[i].LastFetchedAt = nullTimestamp(arg.LastFetchedAt)
+
+	return nil
+}
+
+func (s *Store) TransferFeeds(ctx context.Context, arg database.TransferFeedsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasUser(arg.UserID) {
+		return missing("fk_user_id")
+	}
+
+	for i := range s.feeds {
+		if s.feeds[i].UserID == arg.UserID_2 {
+			s.feeds[i].UserID = arg.UserID
+			s.feeds[i].UpdatedAt = timestamp(arg.UpdatedAt)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) UpdateFeed(ctx context.Context, arg database.UpdateFeedParams) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.feeds, func(f database.Feed) bool { return f.Url == arg.Url && f.ID != arg.ID }); ok {
+		return database.Feed{}, conflict("feeds_url_key")
+	}
+
+	i, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == arg.ID })
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+
+	s.feeds[i].Name = arg.Name
+	s.feeds[i].Url = arg.Url
+	s.feeds[i].UpdatedAt = timestamp(arg.UpdatedAt)
+
+	return s.feeds[i], nil
+}
+
+func (s *Store) UpdateFeedMetadata(ctx context.Context, arg database.UpdateFeedMetadataParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == arg.ID })
+	if !ok {
+		return nil
+	}
+
+	s.feeds[i].Title = arg.Title
+	s.feeds[i].SiteUrl = arg.SiteUrl
+	s.feeds[i].Description = arg.Description
+	s.feeds[i].ImageUrl = arg.ImageUrl
+	s.feeds[i].Language = arg.Language
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CountFilters(ctx context.Context) (database.CountFiltersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return database.CountFiltersRow{
+		Total:    int64(len(s.filters)),
+		ForFeeds: count(s.filters, func(f database.Filter) bool { return f.FeedID.Valid }),
+	}, nil
+}
+
+func (s *Store) CreateFilter(ctx context.Context, arg database.CreateFilterParams) (database.Filter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.filters, func(f database.Filter) bool { return f.ID == arg.ID }); ok {
+		return database.Filter{}, conflict("filters_pkey")
+	}
+	if !s.hasUser(arg.UserID) {
+		return database.Filter{}, missing("fk_user_id")
+	}
+	if arg.FeedID.Valid && !s.hasFeed(arg.FeedID.UUID) {
+		return database.Filter{}, missing("fk_feed_id")
+	}
+	if !slices.Contains([]string{"hide", "only"}, arg.Action) {
+		return database.Filter{}, violates("filters", "filters_action_check")
+	}
+	if !slices.Contains([]string{"title", "description", "any"}, arg.Field) {
+		return database.Filter{}, violates("filters", "filters_field_check")
+	}
+
+	filter := database.Filter{
+		ID:        arg.ID,
+		CreatedAt: timestamp(arg.CreatedAt),
+		UpdatedAt: timestamp(arg.UpdatedAt),
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		Action:    arg.Action,
+		Field:     arg.Field,
+		Pattern:   arg.Pattern,
+	}
+	s.filters = append(s.filters, filter)
+
+	return filter, nil
+}
+
+func (s *Store) DeleteFilter(ctx context.Context, arg database.DeleteFilterParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.filters, _ = remove(s.filters, func(f database.Filter) bool {
+		return f.ID == arg.ID && f.UserID == arg.UserID
+	})
+
+	return nil
+}
+
+func (s *Store) GetFiltersForUser(ctx context.Context, userID uuid.UUID) ([]database.Filter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.Filter
+	for _, f := range s.filters {
+		if f.UserID == userID {
+			items = append(items, f)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Filter) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return items, nil
+}
@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CountPostRevisions(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.postRevisions)), nil
+}
+
+func (s *Store) CountPosts(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.posts)), nil
+}
+
+func (s *Store) CountPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return count(s.posts, func(p database.Post) bool { return s.follows(userID, p.FeedID) }), nil
+}
+
+func (s *Store) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// ON CONFLICT (feed_id, guid) DO NOTHING returns no row
+	if _, ok := find(s.posts, func(p database.Post) bool { return p.FeedID == arg.FeedID && p.Guid == arg.Guid }); ok {
+		return database.Post{}, sql.ErrNoRows
+	}
+	if s.hasPost(arg.ID) {
+		return database.Post{}, conflict("posts_pkey")
+	}
+	if !s.hasFeed(arg.FeedID) {
+		return database.Post{}, missing("fk_feed_id")
+	}
+
+	post := database.Post{
+		ID:          arg.ID,
+		CreatedAt:   timestamp(arg.CreatedAt),
+		UpdatedAt:   timestamp(arg.UpdatedAt),
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: nullTimestamp(arg.PublishedAt),
+		FeedID:      arg.FeedID,
+		Guid:        arg.Guid,
+		Content:     arg.Content,
+		Author:      arg.Author,
+		CommentsUrl: arg.CommentsUrl,
+	}
+	s.posts = append(s.posts, post)
+
+	return post, nil
+}
+
+func (s *Store) CreatePostCategory(ctx context.Context, arg database.CreatePostCategoryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.postCategories, func(c database.PostCategory) bool { return c.PostID == arg.PostID && c.Name == arg.Name }); ok {
+		return nil
+	}
+	if !s.hasPost(arg.PostID) {
+		return missing("fk_post_id")
+	}
+
+	s.postCategories = append(s.postCategories, database.PostCategory{
+		PostID: arg.PostID,
+		Name:   arg.Name,
+	})
+
+	return nil
+}
+
+func (s *Store) CreatePostRevision(ctx context.Context, arg database.CreatePostRevisionParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.postRevisions, func(r database.PostRevision) bool { return r.ID == arg.ID }); ok {
+		return conflict("post_revisions_pkey")
+	}
+	if !s.hasPost(arg.PostID) {
+		return missing("fk_post_id")
+	}
+
+	s.postRevisions = append(s.postRevisions, database.PostRevision{
+		ID:          arg.ID,
+		CreatedAt:   timestamp(arg.CreatedAt),
+		PostID:      arg.PostID,
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: nullTimestamp(arg.PublishedAt),
+	})
+
+	return nil
+}
+
+func (s *Store) DeleteAllPosts(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletePosts(func(database.Post) bool { return true })
+
+	return nil
+}
+
+func (s *Store) DeletePostCategories(ctx context.Context, postID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.postCategories, _ = remove(s.postCategories, func(c database.PostCategory) bool { return c.PostID == postID })
+
+	return nil
+}
+
+func (s *Store) GetCategoriesForPost(ctx context.Context, postID uuid.UUID) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []string
+	for _, c := range s.postCategories {
+		if c.PostID == postID {
+			items = append(items, c.Name)
+		}
+	}
+	slices.Sort(items)
+
+	return items, nil
+}
+
+func (s *Store) GetCategoriesForPostsPage(ctx context.Context, arg database.GetCategoriesForPostsPageParams) ([]database.PostCategory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.PostCategory
+	for _, p := range s.postsPage(arg.UserID, int(arg.Limit), int(arg.Offset)) {
+		for _, c := range s.postCategories {
+			if c.PostID == p.ID {
+				items = append(items, c)
+			}
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.PostCategory) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return items, nil
+}
+
+func (s *Store) GetPostByGUID(ctx context.Context, arg database.GetPostByGUIDParams) (database.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.posts, func(p database.Post) bool { return p.FeedID == arg.FeedID && p.Guid == arg.Guid })
+	if !ok {
+		return database.Post{}, sql.ErrNoRows
+	}
+
+	return s.posts[i], nil
+}
+
+func (s *Store) GetPostsForUser(ctx context.Context, arg database.GetPostsForUserParams) ([]database.GetPostsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.GetPostsForUserRow
+	for _, p := range s.postsPage(arg.UserID, int(arg.Limit), int(arg.Offset)) {
+		f, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == p.FeedID })
+		if !ok {
+			continue
+		}
+		_, updated := find(s.postRevisions, func(r database.PostRevision) bool { return r.PostID == p.ID })
+
+		items = append(items, database.GetPostsForUserRow{
+			ID:          p.ID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			Guid:        p.Guid,
+			Content:     p.Content,
+			Author:      p.Author,
+			CommentsUrl: p.CommentsUrl,
+			Updated:     updated,
+			FeedName:    s.feeds[f].Name,
+		})
+	}
+
+	return items, nil
+}
+
+func (s *Store) GetPostsForUserSince(ctx context.Context, arg database.GetPostsForUserSinceParams) ([]database.GetPostsForUserSinceRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.GetPostsForUserSinceRow
+	for _, p := range s.posts {
+		if !s.follows(arg.UserID, p.FeedID) || p.CreatedAt.Before(arg.CreatedAt) {
+			continue
+		}
+		f, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == p.FeedID })
+		if !ok {
+			continue
+		}
+
+		items = append(items, database.GetPostsForUserSinceRow{
+			ID:          p.ID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			Guid:        p.Guid,
+			Content:     p.Content,
+			Author:      p.Author,
+			CommentsUrl: p.CommentsUrl,
+			FeedName:    s.feeds[f].Name,
+			FeedUrl:     s.feeds[f].Url,
+		})
+	}
+	slices.SortStableFunc(items, func(a, b database.GetPostsForUserSinceRow) int {
+		return cmp.Or(
+			cmp.Compare(a.FeedName, b.FeedName),
+			cmp.Compare(a.FeedUrl, b.FeedUrl),
+			compareNullTimeDesc(a.PublishedAt, b.PublishedAt),
+		)
+	})
+
+	return items, nil
+}
+
+func (s *Store) UpdatePost(ctx context.Context, arg database.UpdatePostParams) (database.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.posts, func(p database.Post) bool { return p.ID == arg.ID })
+	if !ok {
+		return database.Post{}, sql.ErrNoRows
+	}
+
+	s.posts[i].UpdatedAt = timestamp(arg.UpdatedAt)
+	s.posts[i].Title = arg.Title
+	s.posts[i].Url = arg.Url
+	s.posts[i].Description = arg.Description
+	s.posts[i].PublishedAt = nullTimestamp(arg.PublishedAt)
+	s.posts[i].Content = arg.Content
+	s.posts[i].Author = arg.Author
+	s.posts[i].CommentsUrl = arg.CommentsUrl
+
+	return s.posts[i], nil
+}
+
+func (s *Store) UpdatePostGUID(ctx context.Context, arg database.UpdatePostGUIDParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.posts, func(p database.Post) bool { return p.ID == arg.ID })
+	if !ok {
+		return nil
+	}
+	if _, ok := find(s.posts, func(p database.Post) bool {
+		return p.FeedID == s.posts[i].FeedID && p.Guid == arg.Guid && p.ID != arg.ID
+	}); ok {
+		return conflict("posts_feed_id_guid_key")
+	}
+
+	s.posts[i].Guid = arg.Guid
+
+	return nil
+}
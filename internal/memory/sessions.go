@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateSession(ctx context.Context, arg database.CreateSessionParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.sessions, func(se database.Session) bool { return se.ID == arg.ID }); ok {
+		return conflict("sessions_pkey")
+	}
+	if _, ok := find(s.sessions, func(se database.Session) bool { return se.TokenHash == arg.TokenHash }); ok {
+		return conflict("sessions_token_hash_key")
+	}
+	if !s.hasUser(arg.UserID) {
+		return missing("fk_user_id")
+	}
+
+	s.sessions = append(s.sessions, database.Session{
+		ID:        arg.ID,
+		CreatedAt: timestamp(arg.CreatedAt),
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: timestamp(arg.ExpiresAt),
+	})
+
+	return nil
+}
+
+func (s *Store) DeleteSessionsForUser(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions, _ = remove(s.sessions, func(se database.Session) bool { return se.UserID == userID })
+
+	return nil
+}
+
+func (s *Store) GetSessionByTokenHash(ctx context.Context, arg database.GetSessionByTokenHashParams) (database.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.sessions, func(se database.Session) bool {
+		return se.TokenHash == arg.TokenHash && se.ExpiresAt.After(arg.ExpiresAt)
+	})
+	if !ok {
+		return database.Session{}, sql.ErrNoRows
+	}
+
+	return s.sessions[i], nil
+}
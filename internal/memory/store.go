@@ -0,0 +1,275 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Store keeps gator's data in memory. It follows the same rules as the SQL
+// schema (unique constraints, foreign keys, cascading deletes and ordering),
+// so it can stand in for a real database in tests and ephemeral sessions.
+type Store struct {
+	mu sync.Mutex
+	tables
+}
+
+// tables holds a Store's rows, apart from its lock so they can be copied.
+type tables struct {
+	users             []database.User
+	feeds             []database.Feed
+	feedFollows       []database.FeedFollow
+	posts             []database.Post
+	postRevisions     []database.PostRevision
+	postCategories    []database.PostCategory
+	postEnclosures    []database.PostEnclosure
+	sessions          []database.Session
+	downloads         []database.Download
+	filters           []database.Filter
+	webhooks          []database.Webhook
+	webhookDeliveries []database.WebhookDelivery
+}
+
+var _ database.Store = (*Store)(nil)
+
+func New() *Store {
+	return &Store{}
+}
+
+// InTx runs fn against s, and puts every row back the way it was if fn
+// returns an error. Unlike a database transaction it doesn't keep fn's
+// changes from anyone else in the meantime, or theirs from being undone.
+func (s *Store) InTx(ctx context.Context, fn func(database.Store) error) error {
+	s.mu.Lock()
+	saved := s.tables.clone()
+	s.mu.Unlock()
+
+	err := fn(s)
+	if err != nil {
+		s.mu.Lock()
+		s.tables = saved
+		s.mu.Unlock()
+	}
+
+	return err
+}
+
+func (t tables) clone() tables {
+	return tables{
+		users:             slices.Clone(t.users),
+		feeds:             slices.Clone(t.feeds),
+		feedFollows:       slices.Clone(t.feedFollows),
+		posts:             slices.Clone(t.posts),
+		postRevisions:     slices.Clone(t.postRevisions),
+		postCategories:    slices.Clone(t.postCategories),
+		postEnclosures:    slices.Clone(t.postEnclosures),
+		sessions:          slices.Clone(t.sessions),
+		downloads:         slices.Clone(t.downloads),
+		filters:           slices.Clone(t.filters),
+		webhooks:          slices.Clone(t.webhooks),
+		webhookDeliveries: slices.Clone(t.webhookDeliveries),
+	}
+}
+
+// conflict mimics the error a database gives for a broken unique constraint.
+func conflict(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint \"%s\"", constraint)
+}
+
+// missing mimics the error a database gives for a broken foreign key.
+func missing(constraint string) error {
+	return fmt.Errorf("insert or update violates foreign key constraint \"%s\"", constraint)
+}
+
+// violates mimics the error a database gives for a broken check constraint.
+func violates(table, constraint string) error {
+	return fmt.Errorf("new row for relation \"%s\" violates check constraint \"%s\"", table, constraint)
+}
+
+// remove splits items into the ones to keep and the ones drop matches.
+func remove[T any](items []T, drop func(T) bool) ([]T, []T) {
+	kept := items[:0:0]
+	var removed []T
+	for _, item := range items {
+		if drop(item) {
+			removed = append(removed, item)
+			continue
+		}
+		kept = append(kept, item)
+	}
+
+	return kept, removed
+}
+
+func find[T any](items []T, match func(T) bool) (int, bool) {
+	for i, item := range items {
+		if match(item) {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
+func count[T any](items []T, match func(T) bool) int64 {
+	n := int64(0)
+	for _, item := range items {
+		if match(item) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// page applies LIMIT and OFFSET to items.
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
+
+// timestamp drops t's monotonic clock reading, as a round trip through a
+// database would.
+func timestamp(t time.Time) time.Time {
+	return t.Round(0)
+}
+
+func nullTimestamp(t sql.NullTime) sql.NullTime {
+	t.Time = timestamp(t.Time)
+	return t
+}
+
+// compareNullTime orders times oldest first, with NULL after every real
+// time, like ORDER BY ... ASC NULLS LAST.
+func compareNullTime(a, b sql.NullTime) int {
+	switch {
+	case !a.Valid && !b.Valid:
+		return 0
+	case !a.Valid:
+		return 1
+	case !b.Valid:
+		return -1
+	}
+
+	return a.Time.Compare(b.Time)
+}
+
+// compareNullTimeDesc orders times newest first, still with NULL after every
+// real time, like ORDER BY ... DESC NULLS LAST.
+func compareNullTimeDesc(a, b sql.NullTime) int {
+	if a.Valid && b.Valid {
+		return b.Time.Compare(a.Time)
+	}
+
+	return compareNullTime(a, b)
+}
+
+func (s *Store) hasUser(id uuid.UUID) bool {
+	_, ok := find(s.users, func(u database.User) bool { return u.ID == id })
+	return ok
+}
+
+func (s *Store) hasFeed(id uuid.UUID) bool {
+	_, ok := find(s.feeds, func(f database.Feed) bool { return f.ID == id })
+	return ok
+}
+
+func (s *Store) hasPost(id uuid.UUID) bool {
+	_, ok := find(s.posts, func(p database.Post) bool { return p.ID == id })
+	return ok
+}
+
+func (s *Store) follows(userID, feedID uuid.UUID) bool {
+	_, ok := find(s.feedFollows, func(ff database.FeedFollow) bool {
+		return ff.UserID == userID && ff.FeedID == feedID
+	})
+	return ok
+}
+
+// postsPage returns a page of the posts from feeds userID follows, newest
+// first, as GetPostsForUser and the queries for the same page order them.
+func (s *Store) postsPage(userID uuid.UUID, limit, offset int) []database.Post {
+	var posts []database.Post
+	for _, p := range s.posts {
+		if s.follows(userID, p.FeedID) {
+			posts = append(posts, p)
+		}
+	}
+	slices.SortFunc(posts, func(a, b database.Post) int {
+		return cmp.Or(
+			compareNullTimeDesc(a.PublishedAt, b.PublishedAt),
+			bytes.Compare(a.ID[:], b.ID[:]),
+		)
+	})
+
+	return page(posts, limit, offset)
+}
+
+// The delete helpers below cascade the same way the schema's ON DELETE
+// CASCADE foreign keys do.
+
+func (s *Store) deleteUsers(drop func(database.User) bool) {
+	var removed []database.User
+	s.users, removed = remove(s.users, drop)
+
+	for _, u := range removed {
+		s.deleteFeeds(func(f database.Feed) bool { return f.UserID == u.ID })
+		s.feedFollows, _ = remove(s.feedFollows, func(ff database.FeedFollow) bool { return ff.UserID == u.ID })
+		s.filters, _ = remove(s.filters, func(f database.Filter) bool { return f.UserID == u.ID })
+		s.sessions, _ = remove(s.sessions, func(se database.Session) bool { return se.UserID == u.ID })
+		s.deleteWebhooks(func(w database.Webhook) bool { return w.UserID == u.ID })
+	}
+}
+
+func (s *Store) deleteFeeds(drop func(database.Feed) bool) {
+	var removed []database.Feed
+	s.feeds, removed = remove(s.feeds, drop)
+
+	for _, f := range removed {
+		s.deletePosts(func(p database.Post) bool { return p.FeedID == f.ID })
+		s.feedFollows, _ = remove(s.feedFollows, func(ff database.FeedFollow) bool { return ff.FeedID == f.ID })
+		s.filters, _ = remove(s.filters, func(fl database.Filter) bool { return fl.FeedID.Valid && fl.FeedID.UUID == f.ID })
+		s.deleteWebhooks(func(w database.Webhook) bool { return w.FeedID.Valid && w.FeedID.UUID == f.ID })
+	}
+}
+
+func (s *Store) deletePosts(drop func(database.Post) bool) {
+	var removed []database.Post
+	s.posts, removed = remove(s.posts, drop)
+
+	for _, p := range removed {
+		s.postRevisions, _ = remove(s.postRevisions, func(r database.PostRevision) bool { return r.PostID == p.ID })
+		s.postCategories, _ = remove(s.postCategories, func(c database.PostCategory) bool { return c.PostID == p.ID })
+		s.webhookDeliveries, _ = remove(s.webhookDeliveries, func(d database.WebhookDelivery) bool { return d.PostID == p.ID })
+
+		var enclosures []database.PostEnclosure
+		s.postEnclosures, enclosures = remove(s.postEnclosures, func(e database.PostEnclosure) bool { return e.PostID == p.ID })
+		for _, e := range enclosures {
+			s.downloads, _ = remove(s.downloads, func(d database.Download) bool { return d.EnclosureID == e.ID })
+		}
+	}
+}
+
+func (s *Store) deleteWebhooks(drop func(database.Webhook) bool) {
+	var removed []database.Webhook
+	s.webhooks, removed = remove(s.webhooks, drop)
+
+	for _, w := range removed {
+		s.webhookDeliveries, _ = remove(s.webhookDeliveries, func(d database.WebhookDelivery) bool { return d.WebhookID == w.ID })
+	}
+}
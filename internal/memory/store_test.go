@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// fixture is a user following their own feed, which has a post with media
+// that's been downloaded, plus a session, a filter and a webhook.
+type fixture struct {
+	user      database.User
+	feed      database.Feed
+	post      database.Post
+	enclosure database.PostEnclosure
+}
+
+func newFixture(t *testing.T, s *Store, name, feedURL string) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Now()
+	var fx fixture
+	var err error
+
+	fx.user, err = s.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fx.feed, err = s.CreateFeed(ctx, database.CreateFeedParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: name, Url: feedURL, UserID: fx.user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.CreateFeedFollow(ctx, database.CreateFeedFollowParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: fx.user.ID, FeedID: fx.feed.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fx.post, err = s.CreatePost(ctx, database.CreatePostParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, FeedID: fx.feed.ID, Guid: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.CreatePostRevision(ctx, database.CreatePostRevisionParams{ID: uuid.New(), CreatedAt: now, PostID: fx.post.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fx.enclosure = database.PostEnclosure{ID: uuid.New(), PostID: fx.post.ID, Url: feedURL + ".mp3"}
+	err = s.CreatePostEnclosure(ctx, database.CreatePostEnclosureParams{
+		ID:        fx.enclosure.ID,
+		CreatedAt: now,
+		PostID:    fx.post.ID,
+		Url:       fx.enclosure.Url,
+		Source:    "enclosure",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.CreateDownload(ctx, database.CreateDownloadParams{ID: uuid.New(), CreatedAt: now, EnclosureID: fx.enclosure.ID, Path: "/tmp/" + name + ".mp3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.CreateSession(ctx, database.CreateSessionParams{ID: uuid.New(), CreatedAt: now, UserID: fx.user.ID, TokenHash: name, ExpiresAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.CreateFilter(ctx, database.CreateFilterParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: fx.user.ID, Action: "hide", Field: "any", Pattern: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.CreateWebhook(ctx, database.CreateWebhookParams{ID: uuid.New(), CreatedAt: now, UserID: fx.user.ID, Url: "https://example.com/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fx
+}
+
+// counts tallies what s holds, to compare before and after a delete.
+func counts(s *Store) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return map[string]int{
+		"users":          len(s.users),
+		"feeds":          len(s.feeds),
+		"feed follows":   len(s.feedFollows),
+		"posts":          len(s.posts),
+		"post revisions": len(s.postRevisions),
+		"enclosures":     len(s.postEnclosures),
+		"downloads":      len(s.downloads),
+		"sessions":       len(s.sessions),
+		"filters":        len(s.filters),
+		"webhooks":       len(s.webhooks),
+	}
+}
+
+func checkCounts(t *testing.T, s *Store, want map[string]int) {
+	t.Helper()
+
+	for table, n := range counts(s) {
+		if n != want[table] {
+			t.Errorf("%d %s left, want %d", n, table, want[table])
+		}
+	}
+}
+
+func TestDeleteFeedCascades(t *testing.T) {
+	s := New()
+	alice := newFixture(t, s, "alice", "https://example.com/a.xml")
+	newFixture(t, s, "bob", "https://example.com/b.xml")
+
+	err := s.DeleteFeed(context.Background(), alice.feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only alice's feed and what hangs off it are gone
+	checkCounts(t, s, map[string]int{
+		"users":          2,
+		"feeds":          1,
+		"feed follows":   1,
+		"posts":          1,
+		"post revisions": 1,
+		"enclosures":     1,
+		"downloads":      1,
+		"sessions":       2,
+		"filters":        2,
+		"webhooks":       2,
+	})
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	s := New()
+	alice := newFixture(t, s, "alice", "https://example.com/a.xml")
+	bob := newFixture(t, s, "bob", "https://example.com/b.xml")
+
+	// alice also follows bob's feed, which has to survive her
+	_, err := s.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+		ID:     uuid.New(),
+		UserID: alice.user.ID,
+		FeedID: bob.feed.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.DeleteUser(context.Background(), alice.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkCounts(t, s, map[string]int{
+		"users":          1,
+		"feeds":          1,
+		"feed follows":   1,
+		"posts":          1,
+		"post revisions": 1,
+		"enclosures":     1,
+		"downloads":      1,
+		"sessions":       1,
+		"filters":        1,
+		"webhooks":       1,
+	})
+
+	_, err = s.GetSessionByTokenHash(context.Background(), database.GetSessionByTokenHashParams{TokenHash: "alice", ExpiresAt: time.Now()})
+	if err == nil {
+		t.Error("alice's session outlived her")
+	}
+}
+
+func TestCreatePostDedupesGUIDsPerFeed(t *testing.T) {
+	s := New()
+	alice := newFixture(t, s, "alice", "https://example.com/a.xml")
+
+	// ON CONFLICT DO NOTHING leaves RETURNING with no row
+	_, err := s.CreatePost(context.Background(), database.CreatePostParams{ID: uuid.New(), FeedID: alice.feed.ID, Guid: alice.post.Guid})
+	if err != sql.ErrNoRows {
+		t.Errorf("got %v for a guid the feed already has, want sql.ErrNoRows", err)
+	}
+
+	// another feed can have a post with the same guid
+	newFixture(t, s, "bob", "https://example.com/b.xml")
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CountUsers(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.users)), nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.users, func(u database.User) bool { return u.ID == arg.ID }); ok {
+		return database.User{}, conflict("users_pkey")
+	}
+	if _, ok := find(s.users, func(u database.User) bool { return u.Name == arg.Name }); ok {
+		return database.User{}, conflict("users_name_key")
+	}
+
+	user := database.User{
+		ID:           arg.ID,
+		CreatedAt:    timestamp(arg.CreatedAt),
+		UpdatedAt:    timestamp(arg.UpdatedAt),
+		Name:         arg.Name,
+		PasswordHash: arg.PasswordHash,
+	}
+	s.users = append(s.users, user)
+
+	return user, nil
+}
+
+func (s *Store) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteUsers(func(u database.User) bool { return u.ID == id })
+
+	return nil
+}
+
+func (s *Store) GetUser(ctx context.Context, name string) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.users, func(u database.User) bool { return u.Name == name })
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+
+	return s.users[i], nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.users, func(u database.User) bool { return u.ID == id })
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+
+	return s.users[i], nil
+}
+
+func (s *Store) GetUsers(ctx context.Context) ([]database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.User
+	items = append(items, s.users...)
+
+	return items, nil
+}
+
+func (s *Store) RenameUser(ctx context.Context, arg database.RenameUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.users, func(u database.User) bool { return u.Name == arg.Name && u.ID != arg.ID }); ok {
+		return database.User{}, conflict("users_name_key")
+	}
+
+	i, ok := find(s.users, func(u database.User) bool { return u.ID == arg.ID })
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+
+	s.users[i].Name = arg.Name
+	s.users[i].UpdatedAt = timestamp(arg.UpdatedAt)
+
+	return s.users[i], nil
+}
+
+func (s *Store) ResetUsers(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteUsers(func(database.User) bool { return true })
+
+	return nil
+}
+
+func (s *Store) SetUserPassword(ctx context.Context, arg database.SetUserPasswordParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := find(s.users, func(u database.User) bool { return u.ID == arg.ID })
+	if !ok {
+		return nil
+	}
+
+	s.users[i].PasswordHash = arg.PasswordHash
+	s.users[i].UpdatedAt = timestamp(arg.UpdatedAt)
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CountWebhooks(ctx context.Context) (database.CountWebhooksRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return database.CountWebhooksRow{
+		Total:    int64(len(s.webhooks)),
+		ForFeeds: count(s.webhooks, func(w database.Webhook) bool { return w.FeedID.Valid }),
+	}, nil
+}
+
+func (s *Store) CreateWebhook(ctx context.Context, arg database.CreateWebhookParams) (database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.webhooks, func(w database.Webhook) bool { return w.ID == arg.ID }); ok {
+		return database.Webhook{}, conflict("webhooks_pkey")
+	}
+	if !s.hasUser(arg.UserID) {
+		return database.Webhook{}, missing("fk_user_id")
+	}
+	if arg.FeedID.Valid && !s.hasFeed(arg.FeedID.UUID) {
+		return database.Webhook{}, missing("fk_feed_id")
+	}
+
+	webhook := database.Webhook{
+		ID:        arg.ID,
+		CreatedAt: timestamp(arg.CreatedAt),
+		UpdatedAt: timestamp(arg.UpdatedAt),
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		Url:       arg.Url,
+		Keyword:   arg.Keyword,
+	}
+	s.webhooks = append(s.webhooks, webhook)
+
+	return webhook, nil
+}
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := find(s.webhookDeliveries, func(d database.WebhookDelivery) bool { return d.ID == arg.ID }); ok {
+		return conflict("webhook_deliveries_pkey")
+	}
+	if _, ok := find(s.webhooks, func(w database.Webhook) bool { return w.ID == arg.WebhookID }); !ok {
+		return missing("fk_webhook_id")
+	}
+	if !s.hasPost(arg.PostID) {
+		return missing("fk_post_id")
+	}
+
+	s.webhookDeliveries = append(s.webhookDeliveries, database.WebhookDelivery{
+		ID:         arg.ID,
+		CreatedAt:  timestamp(arg.CreatedAt),
+		WebhookID:  arg.WebhookID,
+		PostID:     arg.PostID,
+		Attempt:    arg.Attempt,
+		StatusCode: arg.StatusCode,
+		Error:      arg.Error,
+		Succeeded:  arg.Succeeded,
+	})
+
+	return nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, arg database.DeleteWebhookParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteWebhooks(func(w database.Webhook) bool {
+		return w.ID == arg.ID && w.UserID == arg.UserID
+	})
+
+	return nil
+}
+
+func (s *Store) GetWebhookDeliveriesForUser(ctx context.Context, arg database.GetWebhookDeliveriesForUserParams) ([]database.GetWebhookDeliveriesForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.GetWebhookDeliveriesForUserRow
+	for _, d := range s.webhookDeliveries {
+		w, ok := find(s.webhooks, func(w database.Webhook) bool { return w.ID == d.WebhookID })
+		if !ok || s.webhooks[w].UserID != arg.UserID {
+			continue
+		}
+
+		items = append(items, database.GetWebhookDeliveriesForUserRow{
+			ID:         d.ID,
+			CreatedAt:  d.CreatedAt,
+			WebhookID:  d.WebhookID,
+			PostID:     d.PostID,
+			Attempt:    d.Attempt,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			Succeeded:  d.Succeeded,
+			WebhookUrl: s.webhooks[w].Url,
+		})
+	}
+	slices.SortStableFunc(items, func(a, b database.GetWebhookDeliveriesForUserRow) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return page(items, int(arg.Limit), 0), nil
+}
+
+func (s *Store) GetWebhooksForFeed(ctx context.Context, feedID uuid.NullUUID) ([]database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.Webhook
+	for _, w := range s.webhooks {
+		if w.FeedID.Valid && feedID.Valid && w.FeedID.UUID == feedID.UUID {
+			items = append(items, w)
+			continue
+		}
+		if !w.FeedID.Valid && feedID.Valid && s.follows(w.UserID, feedID.UUID) {
+			items = append(items, w)
+		}
+	}
+
+	return items, nil
+}
+
+func (s *Store) GetWebhooksForUser(ctx context.Context, userID uuid.UUID) ([]database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []database.Webhook
+	for _, w := range s.webhooks {
+		if w.UserID == userID {
+			items = append(items, w)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.Webhook) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return items, nil
+}
@@ -0,0 +1,107 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/sql/schema"
+	"github.com/pressly/goose/v3"
+)
+
+// dir returns where the migrations for dialect live in schema.FS.
+func dir(dialect string) string {
+	if dialect == database.DialectSQLite {
+		return "sqlite"
+	}
+
+	return "."
+}
+
+func setup(dialect string) error {
+	goose.SetBaseFS(schema.FS)
+
+	return goose.SetDialect(dialect)
+}
+
+func Up(db *sql.DB, dialect string) error {
+	err := setup(dialect)
+	if err != nil {
+		return err
+	}
+
+	return goose.Up(db, dir(dialect))
+}
+
+func Down(db *sql.DB, dialect string) error {
+	err := setup(dialect)
+	if err != nil {
+		return err
+	}
+
+	return goose.Down(db, dir(dialect))
+}
+
+func Redo(db *sql.DB, dialect string) error {
+	err := setup(dialect)
+	if err != nil {
+		return err
+	}
+
+	return goose.Redo(db, dir(dialect))
+}
+
+func Status(db *sql.DB, dialect string) error {
+	err := setup(dialect)
+	if err != nil {
+		return err
+	}
+
+	return goose.Status(db, dir(dialect))
+}
+
+// Versions returns the schema version the database is at and the latest
+// version gator knows about.
+func Versions(db *sql.DB, dialect string) (int64, int64, error) {
+	err := setup(dialect)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current, err := goose.GetDBVersionContext(context.Background(), db)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	migrations, err := goose.CollectMigrations(dir(dialect), 0, goose.MaxVersion)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return current, last.Version, nil
+}
+
+// Check returns an error if the database schema is older than this version
+// of gator expects.
+func Check(db *sql.DB, dialect string) error {
+	current, latest, err := Versions(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	if current < latest {
+		return fmt.Errorf(
+			"the database schema is at version %d but gator needs version %d; run 'gator migrate up' to update it",
+			current,
+			latest,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/45uperman/gator/internal/database"
+)
+
+// Queue notifies webhooks in the background, so a slow or unreachable
+// webhook doesn't hold up whoever found the new post. Up to size posts wait
+// in memory until a worker is free to deliver them; past that, Add waits for
+// room. Close delivers whatever is still waiting, so call it before exiting.
+type Queue struct {
+	db     database.Store
+	logger *slog.Logger
+
+	pending chan notification
+	wg      sync.WaitGroup
+}
+
+type notification struct {
+	feed database.Feed
+	post database.Post
+}
+
+// NewQueue starts workers goroutines delivering the posts added to the
+// queue, which holds up to size posts. Deliveries that fail for good are
+// logged to logger, or to slog.Default() if it's nil.
+func NewQueue(db database.Store, workers, size int, logger *slog.Logger) *Queue {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	q := &Queue{
+		db:      db,
+		logger:  logger,
+		pending: make(chan notification, size),
+	}
+
+	q.wg.Add(workers)
+	for range workers {
+		go q.work()
+	}
+
+	return q
+}
+
+// Add queues post, a new post from f, for every webhook interested in it.
+// It returns without waiting for any delivery, unless the queue is full.
+func (q *Queue) Add(f database.Feed, post database.Post) {
+	n := notification{feed: f, post: post}
+
+	select {
+	case q.pending <- n:
+	default:
+		q.logger.Warn(
+			"webhook queue is full, waiting for deliveries to catch up",
+			slog.Int("queued", cap(q.pending)),
+		)
+		q.pending <- n
+	}
+}
+
+// Len returns how many posts are waiting for a worker.
+func (q *Queue) Len() int {
+	return len(q.pending)
+}
+
+// Close stops the queue taking posts and waits for the ones already in it
+// to be delivered.
+func (q *Queue) Close() {
+	close(q.pending)
+	q.wg.Wait()
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for n := range q.pending {
+		err := Notify(context.Background(), q.db, n.feed, n.post)
+		if err != nil {
+			q.logger.Error(
+				"couldn't notify webhooks",
+				slog.String("feed_url", n.feed.Url),
+				slog.String("post_id", n.post.ID.String()),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
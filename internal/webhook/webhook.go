@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/filter"
+	"github.com/google/uuid"
+)
+
+const maxAttempts = 4
+
+// initialBackoff is how long the first retry waits, doubling after that
+var initialBackoff = time.Second
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+type Payload struct {
+	Event string      `json:"event"`
+	Feed  FeedPayload `json:"feed"`
+	Post  PostPayload `json:"post"`
+}
+
+type FeedPayload struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Url  string    `json:"url"`
+}
+
+type PostPayload struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Description string     `json:"description"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+func NewPayload(f database.Feed, post database.Post) Payload {
+	p := Payload{
+		Event: "post.created",
+		Feed: FeedPayload{
+			ID:   f.ID,
+			Name: f.Name,
+			Url:  f.Url,
+		},
+		Post: PostPayload{
+			ID:          post.ID,
+			Title:       post.Title.String,
+			Url:         post.Url.String,
+			Description: post.Description.String,
+		},
+	}
+	if post.PublishedAt.Valid {
+		p.Post.PublishedAt = &post.PublishedAt.Time
+	}
+
+	return p
+}
+
+// Notify fires every webhook interested in a new post from f and waits for
+// the deliveries (including retries) to finish. The error holds every
+// delivery that failed.
+func Notify(ctx context.Context, db database.Store, f database.Feed, post database.Post) error {
+	hooks, err := db.GetWebhooksForFeed(ctx, uuid.NullUUID{UUID: f.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+
+	payload := NewPayload(f, post)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for _, hook := range hooks {
+		if !wants(hook, post) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := Deliver(ctx, db, hook, payload)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("delivering to webhook %s: %w", hook.Url, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func wants(hook database.Webhook, post database.Post) bool {
+	if !hook.Keyword.Valid {
+		return true
+	}
+
+	re, err := filter.ParsePattern(hook.Keyword.String)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(post.Title.String) || re.MatchString(post.Description.String)
+}
+
+// Deliver posts the payload to the webhook, retrying with exponential backoff,
+// and records every attempt in the delivery log. Only network errors, server
+// errors and 429 Too Many Requests are retried, since a webhook that refuses
+// the payload will keep refusing it.
+func Deliver(ctx context.Context, db database.Store, hook database.Webhook, payload Payload) error {
+	backoff := initialBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		status, err := Send(ctx, hook.Url, payload)
+
+		logErr := db.CreateWebhookDelivery(
+			ctx,
+			database.CreateWebhookDeliveryParams{
+				ID:         uuid.New(),
+				CreatedAt:  time.Now(),
+				WebhookID:  hook.ID,
+				PostID:     payload.Post.ID,
+				Attempt:    int32(attempt),
+				StatusCode: sql.NullInt32{Int32: int32(status), Valid: status != 0},
+				Error:      errorString(err),
+				Succeeded:  err == nil,
+			},
+		)
+		if logErr != nil {
+			return logErr
+		}
+
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !retryable(status) {
+			return err
+		}
+		if attempt == maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// Send makes a single delivery attempt and returns the response status, if
+// there was one.
+func Send(ctx context.Context, url string, payload Payload) (int, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gator")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+// retryable reports whether a failed attempt that got status back (zero for
+// no response at all) might succeed if tried again.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+func errorString(err error) sql.NullString {
+	if err == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: err.Error(), Valid: true}
+}
@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/memory"
+	"github.com/google/uuid"
+)
+
+func init() {
+	initialBackoff = time.Millisecond
+}
+
+// stub stands in for a webhook, answering with each of statuses in turn and
+// then 200 OK, and keeps the payloads it was sent.
+type stub struct {
+	mu       sync.Mutex
+	statuses []int
+	received []Payload
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var p Payload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.received = append(s.received, p)
+
+	if len(s.statuses) != 0 {
+		w.WriteHeader(s.statuses[0])
+		s.statuses = s.statuses[1:]
+	}
+}
+
+type fixture struct {
+	db   *memory.Store
+	user database.User
+	feed database.Feed
+	post database.Post
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Now()
+	fx := fixture{db: memory.New()}
+	var err error
+
+	fx.user, err = fx.db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fx.feed, err = fx.db.CreateFeed(ctx, database.CreateFeedParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "blog", Url: "https://example.com/feed.xml", UserID: fx.user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fx.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: fx.user.ID, FeedID: fx.feed.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fx.post, err = fx.db.CreatePost(ctx, database.CreatePostParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Title:     sql.NullString{String: "Launch day", Valid: true},
+		FeedID:    fx.feed.ID,
+		Guid:      "1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fx
+}
+
+func (fx fixture) addWebhook(t *testing.T, url, keyword string) database.Webhook {
+	t.Helper()
+
+	hook, err := fx.db.CreateWebhook(context.Background(), database.CreateWebhookParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    fx.user.ID,
+		Url:       url,
+		Keyword:   sql.NullString{String: keyword, Valid: keyword != ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hook
+}
+
+// deliveries returns the delivery log, first attempt first.
+func (fx fixture) deliveries(t *testing.T) []database.GetWebhookDeliveriesForUserRow {
+	t.Helper()
+
+	rows, err := fx.db.GetWebhookDeliveriesForUser(context.Background(), database.GetWebhookDeliveriesForUserParams{UserID: fx.user.ID, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(rows, func(a, b database.GetWebhookDeliveriesForUserRow) int {
+		return int(a.Attempt - b.Attempt)
+	})
+
+	return rows
+}
+
+func TestDeliverSendsThePayload(t *testing.T) {
+	fx := newFixture(t)
+	webhook := &stub{}
+	srv := httptest.NewServer(webhook)
+	defer srv.Close()
+	hook := fx.addWebhook(t, srv.URL, "")
+
+	err := Deliver(context.Background(), fx.db, hook, NewPayload(fx.feed, fx.post))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(webhook.received) != 1 {
+		t.Fatalf("webhook got %d payloads, want 1", len(webhook.received))
+	}
+	got := webhook.received[0]
+	if got.Event != "post.created" || got.Feed.Url != fx.feed.Url || got.Post.ID != fx.post.ID || got.Post.Title != "Launch day" {
+		t.Errorf("webhook got %+v", got)
+	}
+
+	rows := fx.deliveries(t)
+	if len(rows) != 1 || !rows[0].Succeeded || rows[0].StatusCode.Int32 != 200 || rows[0].Error.Valid {
+		t.Errorf("delivery log has %+v", rows)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		statuses  []int
+		attempts  int
+		succeeded bool
+	}{
+		{"server errors", []int{500, 503}, 3, true},
+		{"rate limits", []int{429}, 2, true},
+		{"until giving up", []int{500, 500, 500, 500, 500}, maxAttempts, false},
+		{"not when refused", []int{404}, 1, false},
+		{"not when the payload is bad", []int{400}, 1, false},
+		{"not when gone", []int{410}, 1, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fx := newFixture(t)
+			srv := httptest.NewServer(&stub{statuses: tc.statuses})
+			defer srv.Close()
+			hook := fx.addWebhook(t, srv.URL, "")
+
+			err := Deliver(context.Background(), fx.db, hook, NewPayload(fx.feed, fx.post))
+			if (err == nil) != tc.succeeded {
+				t.Errorf("got error %v, want success %v", err, tc.succeeded)
+			}
+
+			rows := fx.deliveries(t)
+			if len(rows) != tc.attempts {
+				t.Fatalf("logged %d attempts, want %d", len(rows), tc.attempts)
+			}
+			for i, row := range rows {
+				last := i == len(rows)-1
+				if row.Attempt != int32(i+1) || row.Succeeded != (last && tc.succeeded) {
+					t.Errorf("attempt %d logged as %+v", i+1, row)
+				}
+				if !row.Succeeded && (!row.Error.Valid || row.StatusCode.Int32 != int32(tc.statuses[i])) {
+					t.Errorf("failed attempt %d logged as %+v", i+1, row)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverRetriesNetworkErrors(t *testing.T) {
+	fx := newFixture(t)
+	srv := httptest.NewServer(&stub{})
+	srv.Close()
+	hook := fx.addWebhook(t, srv.URL, "")
+
+	err := Deliver(context.Background(), fx.db, hook, NewPayload(fx.feed, fx.post))
+	if err == nil {
+		t.Fatal("delivered to a closed server")
+	}
+
+	rows := fx.deliveries(t)
+	if len(rows) != maxAttempts {
+		t.Fatalf("logged %d attempts, want %d", len(rows), maxAttempts)
+	}
+	if rows[0].StatusCode.Valid || !rows[0].Error.Valid {
+		t.Errorf("attempt without a response logged as %+v", rows[0])
+	}
+}
+
+func TestQueueDeliversEverythingBeforeClosing(t *testing.T) {
+	fx := newFixture(t)
+	webhook := &stub{}
+	srv := httptest.NewServer(webhook)
+	defer srv.Close()
+	fx.addWebhook(t, srv.URL, "")
+	// only matching posts are sent to a webhook with a keyword
+	fx.addWebhook(t, srv.URL+"/launches", "/launch/i")
+	fx.addWebhook(t, srv.URL+"/other", "release")
+
+	// a queue of one fills up, so Add has to wait for the workers
+	q := NewQueue(fx.db, 2, 1, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for range 3 {
+		q.Add(fx.feed, fx.post)
+	}
+	q.Close()
+
+	if len(webhook.received) != 6 {
+		t.Errorf("webhooks got %d payloads, want 6", len(webhook.received))
+	}
+}
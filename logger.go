@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// newLogger builds a logger that writes records at level or above, as text
+// or JSON, to the file at path (appending) or to stderr if path is empty.
+// The returned func closes the file.
+func newLogger(level, format, path string) (*slog.Logger, func() error, error) {
+	var lvl slog.Level
+	err := lvl.UnmarshalText([]byte(level))
+	if err != nil {
+		return nil, nil, fmt.Errorf("log level must be debug, info, warn or error, not '%s'", level)
+	}
+
+	var newHandler func(io.Writer, *slog.HandlerOptions) slog.Handler
+	switch format {
+	case "text":
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewTextHandler(w, opts) }
+	case "json":
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewJSONHandler(w, opts) }
+	default:
+		return nil, nil, fmt.Errorf("log format must be text or json, not '%s'", format)
+	}
+
+	var w io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		w = f
+		closeFn = f.Close
+	}
+
+	return slog.New(newHandler(w, &slog.HandlerOptions{Level: lvl})), closeFn, nil
+}
@@ -3,109 +3,449 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/45uperman/gator/internal/config"
 	"github.com/45uperman/gator/internal/database"
 	"github.com/45uperman/gator/internal/feed"
+	"github.com/45uperman/gator/internal/filter"
+	"github.com/45uperman/gator/internal/memory"
+	"github.com/45uperman/gator/internal/migrate"
+	"github.com/45uperman/gator/internal/webhook"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db   database.Store
+	conn *sql.DB
+	cfg  *config.Config
 }
 
-type command struct {
-	name string
-	args []string
+// skipSchemaCheck lists the commands that don't need an up to date database,
+// so they still work when it's behind or unreachable.
+var skipSchemaCheck = map[string]bool{
+	"migrate":    true,
+	"profile":    true,
+	"config":     true,
+	"alias":      true,
+	"help":       true,
+	"completion": true,
+	"__complete": true,
 }
 
-type commands struct {
-	supportedCommands map[string]func(*state, command) error
+// skipCreate lists the commands that shouldn't leave a new config file and
+// database behind when there isn't one yet.
+var skipCreate = map[string]bool{
+	"help":       true,
+	"completion": true,
+	"__complete": true,
 }
 
-func (c *commands) run(s *state, cmd command) error {
-	callback, ok := c.supportedCommands[cmd.name]
-	if !ok {
-		return fmt.Errorf("invalid command: %s", cmd.name)
-	}
+func main() {
+	globalFlags := flag.NewFlagSet("gator", flag.ContinueOnError)
+	configPath := globalFlags.String("config", "", "use this config `file` instead of the default one")
+	profile := globalFlags.String("profile", "", "use the profile called `name` instead of the active one")
+	ephemeral := globalFlags.Bool("ephemeral", false, "run commands against a throwaway in-memory database")
+
+	c := commands{globalFlags: globalFlags}
+	registerCommands(&c)
 
-	err := callback(s, cmd)
+	// global flags come before the command, so stop at the first non-flag
+	globalFlags.SetOutput(io.Discard)
+	err := globalFlags.Parse(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		c.printHelp(os.Stdout, nil)
+		return
+	}
 	if err != nil {
-		return err
+		c.reportError(os.Stderr, usageError{err: err})
+		os.Exit(2)
 	}
+	args := globalFlags.Args()
 
-	return nil
-}
-
-func (c *commands) register(name string, f func(*state, command) error) {
-	c.supportedCommands[name] = f
-}
-
-func main() {
 	var s state
 	{
-		cfg, err := config.Read()
-		if err != nil {
+		cfg, err := config.Read(*configPath)
+		noConfig := errors.Is(err, fs.ErrNotExist) && len(args) > 0 && skipCreate[args[0]]
+		if noConfig {
+			cfg, err = config.Default(*configPath)
+		} else if errors.Is(err, fs.ErrNotExist) && !*ephemeral {
+			cfg, err = config.Create(*configPath)
+			if err == nil {
+				// stderr, so this doesn't end up in completions or redirected output
+				fmt.Fprintf(os.Stderr, "Created a new config file at %s using the database %s\n", cfg.Path(), cfg.DBURL)
+				fmt.Fprintln(os.Stderr, "Run 'gator migrate up' to set up the database.")
+			}
+		}
+		if err != nil && !(*ephemeral && errors.Is(err, fs.ErrNotExist)) {
 			log.Fatal(err)
 		}
 
+		if *profile != "" {
+			err = cfg.Select(*profile)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
 		s.cfg = &cfg
 
-		db, err := sql.Open("postgres", s.cfg.DBURL)
-		if err != nil {
-			log.Fatal(err)
+		// completions are read from stdout, so this goes to stderr, and not
+		// for every key press either
+		if len(args) == 0 || args[0] != "__complete" {
+			warnOverrides(s.cfg)
 		}
 
-		dbQueries := database.New(db)
-
-		s.db = dbQueries
+		if noConfig {
+			// there's nothing to read, so don't create the database either
+			s.cfg.Detach()
+			s.db = memory.New()
+		} else if *ephemeral {
+			// start from an empty database and leave the config file alone
+			s.cfg.Detach()
+			s.cfg.CurrentUserName = ""
+			s.cfg.SessionToken = ""
+			s.db = memory.New()
+		} else {
+			db, dbQueries, err := database.Open(s.cfg.DBURL)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			s.db = dbQueries
+			s.conn = db
+		}
 	}
 
-	c := commands{supportedCommands: make(map[string]func(*state, command) error)}
-
-	c.register("login", handlerLogin)
-	c.register("register", handlerRegister)
-	c.register("reset 51420251734", handlerReset)
-	c.register("users", handlerUsers)
-	c.register("agg", handlerAgg)
-	c.register("addfeed", middlewareLoggedIn(handlerAddFeed))
-	c.register("feeds", handlerFeeds)
-	c.register("follow", middlewareLoggedIn(handlerFollow))
-	c.register("following", middlewareLoggedIn(handlerFollowing))
-	c.register("unfollow", middlewareLoggedIn(handlerUnfollow))
-	c.register("browse", middlewareLoggedIn(handlerBrowse))
+	if *ephemeral {
+		err := runEphemeral(&s, &c, args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	if len(os.Args) < 2 {
-		log.Fatal("error: no command given")
+	if len(args) < 1 {
+		c.reportError(os.Stderr, usageError{err: fmt.Errorf("no command given")})
+		os.Exit(2)
 	}
 
 	cmd := command{
-		name: os.Args[1],
-		args: make([]string, 0),
+		name: args[0],
+		args: args[1:],
 	}
 
-	if len(os.Args) > 2 {
-		cmd.args = os.Args[2:]
+	expanded, err := c.expand(&s, cmd)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	err := c.run(&s, cmd)
+	if !skipSchemaCheck[expanded.name] {
+		err := migrate.Check(s.conn, database.Dialect(s.cfg.DBURL))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	err = c.runExpanded(&s, expanded)
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		c.reportError(os.Stderr, err)
+		os.Exit(2)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
-func handlerLogin(s *state, cmd command) error {
-	if len(cmd.args) == 0 {
-		return fmt.Errorf("login requires a username to log in as")
-	}
+func registerCommands(c *commands) {
+	c.register(&commandDef{
+		name:        "help",
+		usage:       "[command] [subcommand]",
+		description: "explain how to use gator or one of its commands",
+		handler:     handlerHelp(c),
+		complete:    completeHelp(c),
+	})
+	c.register(&commandDef{
+		name:        "completion",
+		usage:       "<bash|zsh|fish>",
+		description: "print a script that lets your shell complete gator commands",
+		handler:     handlerCompletion,
+		complete: completeFirst(func(*state) ([]string, error) {
+			return []string{"bash", "zsh", "fish"}, nil
+		}),
+	})
+	c.register(&commandDef{
+		name:    "__complete",
+		usage:   "[word...]",
+		handler: handlerComplete(c),
+		hidden:  true,
+		rawArgs: true,
+	})
+	c.register(&commandDef{
+		name:        "login",
+		usage:       "<username>",
+		description: "log in as a registered user",
+		handler:     handlerLogin,
+		complete:    completeFirst(userNames),
+	})
+	c.register(&commandDef{
+		name:        "register",
+		usage:       "<username>",
+		description: "create a new user and log in as them",
+		handler:     handlerRegister,
+	})
+	c.register(&commandDef{
+		name:        "purge",
+		description: "delete posts, feeds or users from the database",
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("posts", false, "delete all posts")
+			fs.Bool("feeds", false, "delete all feeds, along with their follows and posts")
+			fs.Bool("users", false, "delete all users, along with everything they added")
+			fs.Bool("all", false, "delete everything")
+			fs.Bool("yes", false, "don't ask for confirmation")
+			fs.Bool("dry-run", false, "only show what would be deleted")
+			fs.String("backup", "", "dump the database to this `file` before deleting anything")
+		},
+		handler: handlerPurge,
+	})
+	c.register(&commandDef{
+		name:        "migrate",
+		description: "set up or update the database schema",
+		subcommands: []*commandDef{
+			{name: "up", description: "apply every migration that hasn't been applied yet", handler: handlerMigrate(migrate.Up)},
+			{name: "down", description: "roll back the latest migration", handler: handlerMigrate(migrate.Down)},
+			{name: "status", description: "show which migrations have been applied", handler: handlerMigrate(migrate.Status)},
+			{name: "redo", description: "roll back the latest migration and apply it again", handler: handlerMigrate(migrate.Redo)},
+		},
+	})
+	c.register(&commandDef{
+		name:        "profile",
+		description: "manage the databases gator can switch between",
+		subcommands: []*commandDef{
+			{name: "list", description: "show every profile", handler: handlerProfileList},
+			{name: "use", usage: "<name>", description: "switch to a profile", handler: handlerProfileUse, complete: completeFirst(profileNames)},
+			{name: "add", usage: "<name> <db_url>", description: "add a profile for another database", handler: handlerProfileAdd},
+			{name: "rm", usage: "<name>", description: "remove a profile", handler: handlerProfileRemove, complete: completeFirst(profileNames)},
+		},
+	})
+	c.register(&commandDef{
+		name:        "config",
+		description: "view and change settings",
+		subcommands: []*commandDef{
+			{name: "get", usage: "<key>", description: "print a setting", handler: handlerConfigGet, complete: completeFirst(configKeys)},
+			{name: "set", usage: "<key> <value>", description: "change a setting", handler: handlerConfigSet, complete: completeFirst(configKeys)},
+			{name: "unset", usage: "<key>", description: "clear a setting", handler: handlerConfigUnset, complete: completeFirst(configKeys)},
+			{name: "list", description: "show every setting and what it's for", handler: handlerConfigList},
+			{name: "path", description: "show where the config file is", handler: handlerConfigPath},
+		},
+	})
+	c.register(&commandDef{
+		name:        "alias",
+		description: "manage your shortcuts for commands",
+		subcommands: []*commandDef{
+			{
+				name:        "add",
+				usage:       "<name> <command...>",
+				description: "make a name stand for a command, e.g. 'gator alias add b \"browse --category go 10\"'",
+				handler:     handlerAliasAdd(c),
+			},
+			{name: "rm", usage: "<name>", description: "remove an alias", handler: handlerAliasRemove, complete: completeFirst(aliasNames)},
+			{name: "list", description: "show your aliases", handler: handlerAliasList},
+		},
+	})
+	c.register(&commandDef{
+		name:        "users",
+		description: "list every user",
+		handler:     handlerUsers,
+	})
+	c.register(&commandDef{
+		name:        "deleteuser",
+		usage:       "<username>",
+		description: "delete a user along with their follows, filters and webhooks",
+		flags: func(fs *flag.FlagSet) {
+			fs.String("transfer-to", "", "give the user's feeds to this `user` instead of deleting them")
+			fs.Bool("yes", false, "don't ask for confirmation")
+		},
+		handler:  handlerDeleteUser,
+		complete: completeFirst(userNames),
+	})
+	c.register(&commandDef{
+		name:        "renameuser",
+		usage:       "<old_name> <new_name>",
+		description: "rename a user",
+		handler:     handlerRenameUser,
+		complete:    completeFirst(userNames),
+	})
+	c.register(&commandDef{
+		name:        "whoami",
+		description: "show who you're logged in as",
+		handler:     middlewareLoggedIn(handlerWhoami),
+	})
+	c.register(&commandDef{
+		name:        "passwd",
+		description: "change or remove your password",
+		handler:     middlewareLoggedIn(handlerPasswd),
+	})
+	c.register(&commandDef{
+		name:        "agg",
+		usage:       "<time_between_reps>",
+		description: "keep fetching followed feeds, waiting the given time ('1s', '1m', '1h' etc) between each",
+		flags: func(fs *flag.FlagSet) {
+			fs.String("record", "", "save every fetched feed to this `dir`")
+			fs.String("replay", "", "read feeds from recordings in this `dir` instead of the network")
+			fs.String("log-level", "info", "only log messages at this `level` or above: debug, info, warn or error")
+			fs.String("log-format", "text", "write logs as text or json")
+			fs.String("log-file", "", "append logs to this `file` instead of writing them to stderr")
+		},
+		handler: handlerAgg,
+	})
+	c.register(&commandDef{
+		name:        "addfeed",
+		usage:       "<name> <url>",
+		description: "add a feed and follow it",
+		handler:     middlewareLoggedIn(handlerAddFeed),
+	})
+	c.register(&commandDef{
+		name:        "feeds",
+		description: "list every feed",
+		handler:     handlerFeeds,
+	})
+	c.register(&commandDef{
+		name:        "removefeed",
+		usage:       "<url>",
+		description: "remove a feed you added, along with its posts",
+		flags: func(fs *flag.FlagSet) {
+			fs.Bool("force", false, "remove the feed even if other users follow it")
+		},
+		handler:  middlewareLoggedIn(handlerRemoveFeed),
+		complete: completeFirst(feedURLs),
+	})
+	c.register(&commandDef{
+		name:        "editfeed",
+		usage:       "<url>",
+		description: "change the name or url of a feed you added",
+		flags: func(fs *flag.FlagSet) {
+			fs.String("url", "", "new `url` for the feed")
+			fs.String("name", "", "new `name` for the feed")
+		},
+		handler:  middlewareLoggedIn(handlerEditFeed),
+		complete: completeFirst(feedURLs),
+	})
+	c.register(&commandDef{
+		name:        "renamefeed",
+		usage:       "<url> <new_name>",
+		description: "rename a feed you added",
+		handler:     middlewareLoggedIn(handlerRenameFeed),
+		complete:    completeFirst(feedURLs),
+	})
+	c.register(&commandDef{
+		name:        "follow",
+		usage:       "<url>",
+		description: "follow a feed",
+		handler:     middlewareLoggedIn(handlerFollow),
+		complete:    completeFirst(followedFeedURLs(false)),
+	})
+	c.register(&commandDef{
+		name:        "following",
+		description: "list the feeds you follow",
+		handler:     middlewareLoggedIn(handlerFollowing),
+	})
+	c.register(&commandDef{
+		name:        "unfollow",
+		usage:       "<url>",
+		description: "stop following a feed",
+		handler:     middlewareLoggedIn(handlerUnfollow),
+		complete:    completeFirst(followedFeedURLs(true)),
+	})
+	c.register(&commandDef{
+		name:        "browse",
+		usage:       "[limit]",
+		description: "show the latest posts from the feeds you follow",
+		flags: func(fs *flag.FlagSet) {
+			fs.String("category", "", "only show posts tagged with this `category`")
+			fs.Bool("full", false, "also show each post's full content")
+		},
+		handler: middlewareLoggedIn(handlerBrowse),
+	})
+	c.register(&commandDef{
+		name:        "filter",
+		description: "manage the rules browse uses to hide or pick out posts",
+		subcommands: []*commandDef{
+			{
+				name:        "add",
+				usage:       "<hide|only> <pattern>",
+				description: "add a rule that hides posts matching a pattern, or shows only those",
+				flags: func(fs *flag.FlagSet) {
+					fs.String("feed", "", "only apply the rule to the feed with this `url`")
+					fs.String("field", filter.FieldAny, "which part of a post to match: title, description or any")
+				},
+				handler: middlewareLoggedIn(handlerFilterAdd),
+			},
+			{name: "list", description: "show your rules", handler: middlewareLoggedIn(handlerFilterList)},
+			{name: "rm", usage: "<number>", description: "remove a rule, by its number in the list", handler: middlewareLoggedIn(handlerFilterRemove)},
+			{name: "test", usage: "[limit]", description: "show which of your latest posts your rules would hide", handler: middlewareLoggedIn(handlerFilterTest)},
+		},
+	})
+	c.register(&commandDef{
+		name:        "webhook",
+		description: "manage urls that get told about new posts",
+		subcommands: []*commandDef{
+			{
+				name:        "add",
+				usage:       "<url>",
+				description: "POST new posts to a url",
+				flags: func(fs *flag.FlagSet) {
+					fs.String("feed", "", "only fire for new posts from the feed with this `url`")
+					fs.String("keyword", "", "only fire for posts matching this `pattern`")
+				},
+				handler: middlewareLoggedIn(handlerWebhookAdd),
+			},
+			{name: "list", description: "show your webhooks", handler: middlewareLoggedIn(handlerWebhookList)},
+			{name: "rm", usage: "<number>", description: "remove a webhook, by its number in the list", handler: middlewareLoggedIn(handlerWebhookRemove)},
+			{name: "test", usage: "<number>", description: "send a webhook a sample payload", handler: middlewareLoggedIn(handlerWebhookTest)},
+			{name: "log", usage: "[limit]", description: "show recent delivery attempts", handler: middlewareLoggedIn(handlerWebhookLog)},
+		},
+	})
+	c.register(&commandDef{
+		name:        "digest",
+		description: "summarise the posts saved recently, grouped by feed",
+		flags: func(fs *flag.FlagSet) {
+			fs.String("user", "", "`user` to build the digest for, instead of the current one")
+			fs.String("since", "24h", "how far back to look for new posts ('24h', '7d' etc)")
+			fs.String("out", "", "write the digest to this .eml `file`, or append it to an .mbox file")
+			fs.Bool("send", false, "send the digest through the configured SMTP server")
+			fs.String("to", "", "`address` to send the digest to, instead of smtp.to from the config")
+		},
+		handler: handlerDigest,
+	})
+	c.register(&commandDef{
+		name:        "download",
+		description: "download the media attached to recent posts",
+		flags: func(fs *flag.FlagSet) {
+			fs.String("feed", "", "only download media from the feed with this `url`")
+			fs.String("since", "7d", "only download media from posts saved this recently ('24h', '7d' etc)")
+			fs.String("dir", "", "`dir`ectory to download media into")
+			fs.String("max-size", "", "skip files larger than this ('500MB', '2GB' etc)")
+		},
+		handler: middlewareLoggedIn(handlerDownload),
+	})
+}
 
-	_, err := s.db.GetUser(context.Background(), cmd.args[0])
+func handlerLogin(s *state, cmd command) error {
+	user, err := s.db.GetUser(context.Background(), cmd.args[0])
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("cannot log in as user with name %s because that user does not exist", cmd.args[0])
@@ -113,7 +453,24 @@ func handlerLogin(s *state, cmd command) error {
 		return err
 	}
 
-	err = s.cfg.SetUser(cmd.args[0])
+	err = authenticate(user)
+	if err != nil {
+		return err
+	}
+
+	// a user has one login at a time, so a token left in an old config
+	// stops working
+	err = s.db.DeleteSessionsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	token, err := startSession(s, user)
+	if err != nil {
+		return err
+	}
+
+	err = s.cfg.SetUser(user.Name, token)
 	if err != nil {
 		return err
 	}
@@ -124,10 +481,6 @@ func handlerLogin(s *state, cmd command) error {
 }
 
 func handlerRegister(s *state, cmd command) error {
-	if len(cmd.args) == 0 {
-		return fmt.Errorf("register requires a username to register")
-	}
-
 	_, err := s.db.GetUser(context.Background(), cmd.args[0])
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -137,20 +490,34 @@ func handlerRegister(s *state, cmd command) error {
 		return fmt.Errorf("cannot create user with name %s because that user already exists", cmd.args[0])
 	}
 
+	passwordHash, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+
 	u, err := s.db.CreateUser(
 		context.Background(),
 		database.CreateUserParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Name:      cmd.args[0],
+			ID:           uuid.New(),
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+			Name:         cmd.args[0],
+			PasswordHash: passwordHash,
 		},
 	)
 	if err != nil {
 		return err
 	}
 
-	s.cfg.SetUser(cmd.args[0])
+	token, err := startSession(s, u)
+	if err != nil {
+		return err
+	}
+
+	err = s.cfg.SetUser(u.Name, token)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("\nsuccessfully created user: %s\n\n", cmd.args[0])
 
 	fmt.Printf(
@@ -164,15 +531,6 @@ func handlerRegister(s *state, cmd command) error {
 	return nil
 }
 
-func handlerReset(s *state, cmd command) error {
-	err := s.db.ResetUsers(context.Background())
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func handlerUsers(s *state, cmd command) error {
 	users, err := s.db.GetUsers(context.Background())
 	if err != nil {
@@ -191,31 +549,59 @@ func handlerUsers(s *state, cmd command) error {
 }
 
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("agg requires a time_between_reps duration string ('1s', '1m', '1h'm etc) as an argument")
+	record := cmd.stringFlag("record")
+	replay := cmd.stringFlag("replay")
+	if record != "" && replay != "" {
+		return cmd.usageErrorf("agg can't --record and --replay at the same time")
 	}
 
 	timeBetweenReps, err := time.ParseDuration(cmd.args[0])
+	if err != nil {
+		return cmd.usageErrorf("agg takes a duration ('1s', '1m', '1h' etc), not '%s'", cmd.args[0])
+	}
+
+	logger, closeLog, err := newLogger(cmd.stringFlag("log-level"), cmd.stringFlag("log-format"), cmd.stringFlag("log-file"))
 	if err != nil {
 		return err
 	}
+	defer closeLog()
 
-	fmt.Printf("Collecting feeds every %v\n", timeBetweenReps)
+	webhooks := webhook.NewQueue(s.db, 4, 1000, logger)
+	defer webhooks.Close()
+
+	// stop between feeds on Ctrl-C, so the deferred Close can deliver what's
+	// queued; a second Ctrl-C quits straight away
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scraper := feed.Scraper{DB: s.db, Logger: logger, Webhooks: webhooks}
+	if record != "" {
+		scraper.Fetcher = feed.Recorder{Dir: record}
+	}
+	if replay != "" {
+		scraper.Fetcher = feed.Replayer{Dir: replay}
+	}
+
+	logger.Info("collecting feeds", slog.Duration("every", timeBetweenReps))
 
 	ticker := time.NewTicker(timeBetweenReps)
-	for ; ; <-ticker.C {
-		err := feed.ScrapeFeeds(s.db)
+	for {
+		err := scraper.ScrapeFeeds()
 		if err != nil {
 			return err
 		}
+
+		select {
+		case <-ctx.Done():
+			stop()
+			logger.Info("stopping, after delivering queued webhooks", slog.Int("queued", webhooks.Len()))
+			return nil
+		case <-ticker.C:
+		}
 	}
 }
 
 func handlerAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 2 {
-		return fmt.Errorf("addfeed requires the name and url of the feed to be added as arguments")
-	}
-
 	f, err := s.db.CreateFeed(
 		context.Background(),
 		database.CreateFeedParams{
@@ -273,7 +659,7 @@ func handlerFeeds(s *state, cmd command) error {
 
 		fmt.Printf("\nFeed '%s' added by user '%s'\n\n", f.Name, feedOwner.Name)
 		fmt.Printf(
-			"  ID: %v\n  CreatedAt: %v\n  UpdatedAt: %v\n  Name: %v\n  Url: %v\n  UserId: %v\n\n",
+			"  ID: %v\n  CreatedAt: %v\n  UpdatedAt: %v\n  Name: %v\n  Url: %v\n  UserId: %v\n",
 			f.ID,
 			f.CreatedAt,
 			f.UpdatedAt,
@@ -281,16 +667,28 @@ func handlerFeeds(s *state, cmd command) error {
 			f.Url,
 			f.UserID,
 		)
+
+		for _, field := range []struct {
+			label string
+			value sql.NullString
+		}{
+			{"Title", f.Title},
+			{"Site", f.SiteUrl},
+			{"Description", f.Description},
+			{"Image", f.ImageUrl},
+			{"Language", f.Language},
+		} {
+			if field.value.Valid {
+				fmt.Printf("  %s: %v\n", field.label, field.value.String)
+			}
+		}
+		fmt.Print("\n")
 	}
 
 	return nil
 }
 
 func handlerFollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("follow requires the url of the feed to be followed as an argument")
-	}
-
 	f, err := s.db.GetFeedByURL(
 		context.Background(),
 		cmd.args[0],
@@ -335,10 +733,6 @@ func handlerFollowing(s *state, cmd command, user database.User) error {
 }
 
 func handlerUnfollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("unfollow requires the url of the feed to be unfollowed as an argument")
-	}
-
 	feedID, err := s.db.UnfollowUserFromFeed(
 		context.Background(),
 		database.UnfollowUserFromFeedParams{
@@ -365,48 +759,37 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 	return nil
 }
 
-func handlerBrowse(s *state, cmd command, user database.User) error {
-	limit := 2
-	if len(cmd.args) != 0 {
-		_, err := fmt.Sscanf(cmd.args[0], "%d", &limit)
+func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
+	return func(s *state, cmd command) error {
+		current_user, err := s.db.GetUser(
+			context.Background(),
+			s.cfg.CurrentUserName,
+		)
 		if err != nil {
-			return fmt.Errorf("browse takes an integer as an optional argument, not '%s'", cmd.args[0])
+			return err
 		}
-	}
 
-	posts, err := s.db.GetPostsForUser(
-		context.Background(),
-		database.GetPostsForUserParams{
-			UserID: user.ID,
-			Limit:  int32(limit),
-		},
-	)
-	if err != nil {
-		return err
-	}
-
-	for _, post := range posts {
-		f, err := s.db.GetFeed(context.Background(), post.FeedID)
+		err = authorize(s, current_user)
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("%s - '%s'\n", f.Name, post.Title.String)
+		return handler(s, cmd, current_user)
 	}
-
-	return nil
 }
 
-func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
-	return func(s *state, cmd command) error {
-		current_user, err := s.db.GetUser(
-			context.Background(),
-			s.cfg.CurrentUserName,
-		)
-		if err != nil {
-			return err
+// warnOverrides points out settings that the environment has changed from
+// what's in the config file, since 'gator config get' still shows the file's.
+func warnOverrides(cfg *config.Config) {
+	for _, name := range []string{"db_url", "current_user_name"} {
+		env := cfg.OverriddenBy(name)
+		if env == "" {
+			continue
 		}
 
-		return handler(s, cmd, current_user)
+		stored, err := cfg.Get(name)
+		if err == nil && stored != os.Getenv(env) {
+			fmt.Fprintf(os.Stderr, "Note: using %s from %s instead of the config file\n", name, env)
+		}
 	}
 }
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/auth"
+	"github.com/45uperman/gator/internal/config"
+	"github.com/45uperman/gator/internal/database"
+	"github.com/45uperman/gator/internal/memory"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	// gator runs the test binary as gator itself
+	if os.Getenv("GATOR_TEST_MAIN") == "1" {
+		os.Args = append([]string{"gator"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+
+	// nobody is around to answer prompts, so they all fail
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		panic(err)
+	}
+	os.Stdin = devNull
+	stdin = bufio.NewReader(devNull)
+
+	os.Exit(m.Run())
+}
+
+// gator runs the real gator with args in an environment of its own, where
+// HOME is home, and returns what it wrote to stdout and stderr.
+func gator(t *testing.T, home string, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = []string{"GATOR_TEST_MAIN=1", "HOME=" + home, "PATH=" + os.Getenv("PATH")}
+	var out, errOut strings.Builder
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	err = cmd.Run()
+	return out.String(), errOut.String(), err
+}
+
+// newTestState returns a state backed by an empty in-memory database and a
+// config that's never written to disk, along with every gator command.
+func newTestState(t *testing.T) (*state, *commands) {
+	t.Helper()
+
+	for _, env := range []string{"GATOR_USER", "GATOR_DB_URL", "DATABASE_URL"} {
+		t.Setenv(env, "")
+	}
+
+	cfg := config.Config{Profile: config.DefaultProfile}
+	cfg.Detach()
+
+	c := &commands{globalFlags: flag.NewFlagSet("gator", flag.ContinueOnError)}
+	registerCommands(c)
+
+	return &state{db: memory.New(), cfg: &cfg}, c
+}
+
+// run runs a gator command line, like "addfeed blog https://example.com".
+func run(t *testing.T, s *state, c *commands, line string) error {
+	t.Helper()
+
+	words := strings.Fields(line)
+	return c.run(s, command{name: words[0], args: words[1:]})
+}
+
+func mustRun(t *testing.T, s *state, c *commands, line string) {
+	t.Helper()
+
+	err := run(t, s, c, line)
+	if err != nil {
+		t.Fatalf("gator %s: %v", line, err)
+	}
+}
+
+// output runs a gator command line like run, and returns what it printed.
+func output(t *testing.T, s *state, c *commands, line string) (string, error) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	printed := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		printed <- string(b)
+	}()
+
+	err = run(t, s, c, line)
+	w.Close()
+
+	return <-printed, err
+}
+
+func addUser(t *testing.T, s *state, name, password string) database.User {
+	t.Helper()
+
+	var hash sql.NullString
+	if password != "" {
+		h, err := auth.HashPassword(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash = sql.NullString{String: h, Valid: true}
+	}
+
+	user, err := s.db.CreateUser(context.Background(), database.CreateUserParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		Name:         name,
+		PasswordHash: hash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+func logIn(t *testing.T, s *state, user database.User) {
+	t.Helper()
+
+	token, err := startSession(s, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.cfg.SetUser(user.Name, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHelpDoesntCreateAConfig(t *testing.T) {
+	home := t.TempDir()
+
+	for _, args := range [][]string{{"help"}, {"completion", "bash"}, {"__complete", "a"}} {
+		_, stderr, err := gator(t, home, args...)
+		if err != nil {
+			t.Fatalf("gator %s: %v\n%s", strings.Join(args, " "), err, stderr)
+		}
+	}
+
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("left %v in HOME", entries)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/45uperman/gator/internal/auth"
+	"github.com/45uperman/gator/internal/database"
+	"golang.org/x/term"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func prompt(question string) (string, error) {
+	fmt.Print(question)
+
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+// confirm asks the user to type expected back before doing something that
+// can't be undone.
+func confirm(question, expected string) (bool, error) {
+	answer, err := prompt(fmt.Sprintf("%s\nType '%s' to confirm: ", question, expected))
+	if err != nil {
+		return false, err
+	}
+
+	return answer == expected, nil
+}
+
+// promptPassword reads a password without echoing it. When stdin isn't a
+// terminal (e.g. it's piped in from a script) it reads a plain line instead.
+func promptPassword(question string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(question)
+	}
+
+	fmt.Print(question)
+	password, err := term.ReadPassword(fd)
+	fmt.Print("\n")
+	if err != nil {
+		return "", err
+	}
+
+	return string(password), nil
+}
+
+// promptNewPassword asks for a password twice. An empty answer means no
+// password.
+func promptNewPassword() (sql.NullString, error) {
+	password, err := promptPassword("New password (leave empty for none): ")
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	if password == "" {
+		return sql.NullString{}, nil
+	}
+
+	again, err := promptPassword("Repeat new password: ")
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	if again != password {
+		return sql.NullString{}, fmt.Errorf("passwords do not match")
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: hash, Valid: true}, nil
+}
+
+// authenticate makes sure whoever is running gator knows user's password, if
+// they have one.
+func authenticate(user database.User) error {
+	if !user.PasswordHash.Valid {
+		return nil
+	}
+
+	password, err := promptPassword(fmt.Sprintf("Password for %s: ", user.Name))
+	if err != nil {
+		return err
+	}
+
+	err = auth.CheckPassword(user.PasswordHash.String, password)
+	if err == auth.ErrWrongPassword {
+		return fmt.Errorf("wrong password for user %s", user.Name)
+	}
+
+	return err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/45uperman/gator/internal/auth"
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// sessionLifetime is how long a login lasts before the password is needed
+// again.
+const sessionLifetime = 30 * 24 * time.Hour
+
+// startSession records that user just logged in, and returns the token that
+// proves it, to keep in the config. Users without a password don't need one.
+func startSession(s *state, user database.User) (string, error) {
+	if !user.PasswordHash.Valid {
+		return "", nil
+	}
+
+	token := auth.NewToken()
+	err := s.db.CreateSession(
+		context.Background(),
+		database.CreateSessionParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UserID:    user.ID,
+			TokenHash: auth.HashToken(token),
+			ExpiresAt: time.Now().Add(sessionLifetime),
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// loggedInAs reports whether the session token in the config belongs to
+// user and hasn't expired. The name in the config alone proves nothing, since
+// anyone can edit it.
+func loggedInAs(s *state, user database.User) (bool, error) {
+	if s.cfg.SessionToken == "" {
+		return false, nil
+	}
+
+	session, err := s.db.GetSessionByTokenHash(
+		context.Background(),
+		database.GetSessionByTokenHashParams{
+			TokenHash: auth.HashToken(s.cfg.SessionToken),
+			ExpiresAt: time.Now(),
+		},
+	)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return session.UserID == user.ID, nil
+}
+
+// authorize makes sure whoever is running gator may act as user: anyone may
+// if user has no password, otherwise they need to have logged in as user or
+// to give their password now.
+func authorize(s *state, user database.User) error {
+	if !user.PasswordHash.Valid {
+		return nil
+	}
+
+	ok, err := loggedInAs(s, user)
+	if err != nil || ok {
+		return err
+	}
+
+	return authenticate(user)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/45uperman/gator/internal/auth"
+	"github.com/45uperman/gator/internal/config"
+	"github.com/45uperman/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestPasswordsNeedALogin(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "password1")
+	bob := addUser(t, s, "bob", "password2")
+
+	// naming a user in the config isn't enough
+	err := s.cfg.SetUser("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run(t, s, c, "whoami"); err == nil {
+		t.Error("whoami worked without a session")
+	}
+
+	// nor is someone else's session
+	logIn(t, s, bob)
+	err = s.cfg.SetUser("alice", s.cfg.SessionToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run(t, s, c, "whoami"); err == nil {
+		t.Error("whoami worked with another user's session")
+	}
+
+	logIn(t, s, alice)
+	mustRun(t, s, c, "whoami")
+
+	// and being logged in doesn't make other users' passwords optional
+	if err := run(t, s, c, "deleteuser --yes bob"); err == nil {
+		t.Error("deleteuser worked without bob's password")
+	}
+}
+
+func TestSessionsExpire(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "password1")
+
+	token := auth.NewToken()
+	err := s.db.CreateSession(context.Background(), database.CreateSessionParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().Add(-sessionLifetime - time.Hour),
+		UserID:    alice.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.cfg.SetUser("alice", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(t, s, c, "whoami"); err == nil {
+		t.Error("whoami worked with an expired session")
+	}
+}
+
+func TestLoginEndsEarlierSessions(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "password1")
+	logIn(t, s, alice)
+	earlier := s.cfg.SessionToken
+
+	defer func(r *bufio.Reader) { stdin = r }(stdin)
+	stdin = bufio.NewReader(strings.NewReader("password1\n"))
+	mustRun(t, s, c, "login alice")
+	mustRun(t, s, c, "whoami")
+
+	_, err := s.db.GetSessionByTokenHash(context.Background(), database.GetSessionByTokenHashParams{
+		TokenHash: auth.HashToken(earlier),
+		ExpiresAt: time.Now(),
+	})
+	if err != sql.ErrNoRows {
+		t.Errorf("the earlier session is still there: %v", err)
+	}
+}
+
+func TestGatorUserNeedsAPassword(t *testing.T) {
+	s, c := newTestState(t)
+	alice := addUser(t, s, "alice", "password1")
+	logIn(t, s, alice)
+
+	t.Setenv("GATOR_USER", "alice")
+	err := s.cfg.Select(config.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(t, s, c, "whoami"); err == nil {
+		t.Error("whoami worked for GATOR_USER without a password")
+	}
+}
+
+func TestGatorUserIsntSaved(t *testing.T) {
+	s, c := newTestState(t)
+	addUser(t, s, "alice", "")
+	bob := addUser(t, s, "bob", "")
+	logIn(t, s, bob)
+
+	t.Setenv("GATOR_USER", "alice")
+	err := s.cfg.Select(config.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustRun(t, s, c, "renameuser alice al")
+	mustRun(t, s, c, "deleteuser --yes al")
+
+	if p := s.cfg.GetProfile(config.DefaultProfile); p.CurrentUserName != "bob" {
+		t.Errorf("config file has %q logged in, want bob", p.CurrentUserName)
+	}
+}
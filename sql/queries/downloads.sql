@@ -0,0 +1,22 @@
+-- name: CreateDownload :one
+INSERT INTO downloads (id, created_at, enclosure_id, path, size, sha256)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetDownloadByPath :one
+SELECT * FROM downloads WHERE downloads.path = $1;
+
+-- name: GetDownloadsForEnclosure :many
+SELECT * FROM downloads
+WHERE downloads.enclosure_id = $1
+ORDER BY downloads.created_at ASC;
+
+-- name: CountDownloads :one
+SELECT COUNT(*) FROM downloads;
\ No newline at end of file
@@ -0,0 +1,54 @@
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (id, created_at, post_id, url, source, media_type, length, duration, episode, image_url, thumbnail_url)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
+)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetEnclosuresForPost :many
+SELECT * FROM post_enclosures
+WHERE post_enclosures.post_id = $1
+ORDER BY post_enclosures.created_at ASC;
+
+-- name: GetEnclosuresForPostsPage :many
+-- the media of the posts GetPostsForUser returns for the same page
+SELECT post_enclosures.* FROM post_enclosures
+WHERE post_enclosures.post_id IN (
+    SELECT posts.id FROM posts
+    INNER JOIN feed_follows
+    ON posts.feed_id = feed_follows.feed_id
+    WHERE feed_follows.user_id = $1
+    ORDER BY posts.published_at DESC NULLS LAST, posts.id ASC
+    LIMIT $2
+    OFFSET $3
+)
+ORDER BY post_enclosures.created_at ASC;
+
+-- name: GetEnclosuresForUser :many
+SELECT
+    post_enclosures.*,
+    posts.title AS post_title,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM post_enclosures
+INNER JOIN posts
+ON post_enclosures.post_id = posts.id
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = $1 AND posts.created_at >= $2
+ORDER BY posts.published_at ASC NULLS LAST;
+
+-- name: CountPostEnclosures :one
+SELECT COUNT(*) FROM post_enclosures;
\ No newline at end of file
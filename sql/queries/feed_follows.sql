@@ -1,25 +1,16 @@
 -- name: CreateFeedFollow :one
-WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-    VALUES (
-        $1,
-        $2,
-        $3,
-        $4,
-        $5
-    )
-
-    RETURNING *
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
 )
-SELECT
-    inserted_feed_follow.*,
-    feeds.name AS feed_name,
-    users.name AS user_name
-FROM inserted_feed_follow
-INNER JOIN feeds
-ON inserted_feed_follow.feed_id = feeds.id
-INNER JOIN users
-ON inserted_feed_follow.user_id = users.id;
+RETURNING
+    *,
+    (SELECT feeds.name FROM feeds WHERE feeds.id = feed_follows.feed_id) AS feed_name,
+    (SELECT users.name FROM users WHERE users.id = feed_follows.user_id) AS user_name;
 
 -- name: GetFeedFollowsForUser :many
 SELECT
@@ -34,10 +25,15 @@ ON feed_follows.user_id = users.id
 WHERE  users.name = $1;
 
 -- name: UnfollowUserFromFeed :one
-WITH feed_id AS (
+DELETE FROM feed_follows
+WHERE feed_follows.feed_id = (
     SELECT feeds.id FROM feeds
     WHERE feeds.url = $1
-)
-DELETE FROM feed_follows
-WHERE feed_follows.feed_id = feed_id AND feed_follows.user_id = $2
-RETURNING feed_follows.feed_id;
\ No newline at end of file
+) AND feed_follows.user_id = $2
+RETURNING feed_follows.feed_id;
+
+-- name: CountFeedFollowsForUser :one
+SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = $1;
+
+-- name: CountFeedFollows :one
+SELECT COUNT(*) FROM feed_follows;
\ No newline at end of file
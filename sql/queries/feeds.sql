@@ -19,11 +19,50 @@ SELECT * FROM feeds WHERE feeds.id = $1;
 -- name: GetFeedByURL :one
 SELECT * FROM feeds WHERE feeds.url = $1;
 
+-- name: UpdateFeed :one
+UPDATE feeds
+SET name = $1, url = $2, updated_at = $3
+WHERE feeds.id = $4
+RETURNING *;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE feeds.id = $1;
+
+-- name: CountFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1;
+
+-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts WHERE posts.feed_id = $1;
+
+-- name: ClearFeedLegacyGUIDs :exec
+UPDATE feeds
+SET legacy_guids = FALSE
+WHERE feeds.id = $1;
+
 -- name: MarkFeedFetched :exec
 UPDATE feeds
 SET last_fetched_at = $1
 WHERE feeds.id = $2;
 
+-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET title = $1, site_url = $2, description = $3, image_url = $4, language = $5
+WHERE feeds.id = $6;
+
 -- name: GetNextFeedToFetch :one
 SELECT * FROM feeds
-ORDER BY feeds.last_fetched_at ASC NULLS FIRST;
\ No newline at end of file
+ORDER BY feeds.last_fetched_at ASC NULLS FIRST;
+
+-- name: TransferFeeds :exec
+UPDATE feeds
+SET user_id = $1, updated_at = $2
+WHERE feeds.user_id = $3;
+
+-- name: CountFeedsForUser :one
+SELECT COUNT(*) FROM feeds WHERE feeds.user_id = $1;
+
+-- name: CountFeeds :one
+SELECT COUNT(*) FROM feeds;
+
+-- name: DeleteAllFeeds :exec
+DELETE FROM feeds;
\ No newline at end of file
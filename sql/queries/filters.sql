@@ -0,0 +1,25 @@
+-- name: CreateFilter :one
+INSERT INTO filters (id, created_at, updated_at, user_id, feed_id, action, field, pattern)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING *;
+
+-- name: GetFiltersForUser :many
+SELECT * FROM filters
+WHERE filters.user_id = $1
+ORDER BY filters.created_at ASC;
+
+-- name: DeleteFilter :exec
+DELETE FROM filters
+WHERE filters.id = $1 AND filters.user_id = $2;
+
+-- name: CountFilters :one
+SELECT COUNT(*) AS total, COUNT(filters.feed_id) AS for_feeds FROM filters;
\ No newline at end of file
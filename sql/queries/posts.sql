@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content, author, comments_url)
 VALUES (
     $1,
     $2,
@@ -8,16 +8,115 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10,
+    $11,
+    $12
 )
+ON CONFLICT (feed_id, guid) DO NOTHING
 RETURNING *;
 
 -- name: GetPostsForUser :many
-WITH followed_feeds AS (
-    SELECT * FROM feed_follows
+SELECT
+    posts.*,
+    EXISTS (
+        SELECT 1 FROM post_revisions
+        WHERE post_revisions.post_id = posts.id
+    ) AS updated,
+    feeds.name AS feed_name
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY posts.published_at DESC NULLS LAST, posts.id ASC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetPostsForUserSince :many
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON posts.feed_id = feeds.id
+WHERE feed_follows.user_id = $1 AND posts.created_at >= $2
+ORDER BY feeds.name ASC, feeds.url ASC, posts.published_at DESC NULLS LAST;
+
+-- name: GetPostByGUID :one
+SELECT * FROM posts
+WHERE posts.feed_id = $1 AND posts.guid = $2;
+
+-- name: UpdatePost :one
+UPDATE posts
+SET updated_at = $1, title = $2, url = $3, description = $4, published_at = $5, content = $6, author = $7, comments_url = $8
+WHERE posts.id = $9
+RETURNING *;
+
+-- name: UpdatePostGUID :exec
+UPDATE posts
+SET guid = $1
+WHERE posts.id = $2;
+
+-- name: CreatePostRevision :exec
+INSERT INTO post_revisions (id, created_at, post_id, title, url, description, published_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+);
+
+-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT DO NOTHING;
+
+-- name: GetCategoriesForPost :many
+SELECT post_categories.name FROM post_categories
+WHERE post_categories.post_id = $1
+ORDER BY post_categories.name ASC;
+
+-- name: GetCategoriesForPostsPage :many
+-- the categories of the posts GetPostsForUser returns for the same page
+SELECT post_categories.* FROM post_categories
+WHERE post_categories.post_id IN (
+    SELECT posts.id FROM posts
+    INNER JOIN feed_follows
+    ON posts.feed_id = feed_follows.feed_id
     WHERE feed_follows.user_id = $1
+    ORDER BY posts.published_at DESC NULLS LAST, posts.id ASC
+    LIMIT $2
+    OFFSET $3
 )
-SELECT * FROM posts, followed_feeds
-WHERE posts.feed_id = followed_feeds.feed_id
-ORDER BY posts.published_at DESC
-LIMIT $2;
\ No newline at end of file
+ORDER BY post_categories.name ASC;
+
+-- name: DeletePostCategories :exec
+DELETE FROM post_categories
+WHERE post_categories.post_id = $1;
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts
+INNER JOIN feed_follows
+ON posts.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1;
+
+-- name: CountPosts :one
+SELECT COUNT(*) FROM posts;
+
+-- name: CountPostRevisions :one
+SELECT COUNT(*) FROM post_revisions;
+
+-- name: DeleteAllPosts :exec
+DELETE FROM posts;
\ No newline at end of file
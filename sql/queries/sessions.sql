@@ -0,0 +1,15 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (id, created_at, user_id, token_hash, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: GetSessionByTokenHash :one
+SELECT * FROM sessions WHERE sessions.token_hash = $1 AND sessions.expires_at > $2;
+
+-- name: DeleteSessionsForUser :exec
+DELETE FROM sessions WHERE sessions.user_id = $1;
\ No newline at end of file
@@ -1,10 +1,11 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, password_hash)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5
 )
 RETURNING *;
 
@@ -18,4 +19,21 @@ SELECT * FROM users WHERE users.id = $1;
 SELECT * FROM users;
 
 -- name: ResetUsers :exec
-DELETE FROM users;
\ No newline at end of file
+DELETE FROM users;
+
+-- name: RenameUser :one
+UPDATE users
+SET name = $1, updated_at = $2
+WHERE users.id = $3
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE users.id = $1;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $1, updated_at = $2
+WHERE users.id = $3;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
\ No newline at end of file
@@ -0,0 +1,59 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, feed_id, url, keyword)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT * FROM webhooks
+WHERE webhooks.user_id = $1
+ORDER BY webhooks.created_at ASC;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE webhooks.id = $1 AND webhooks.user_id = $2;
+
+-- name: GetWebhooksForFeed :many
+SELECT * FROM webhooks
+WHERE webhooks.feed_id = $1
+OR (
+    webhooks.feed_id IS NULL
+    AND webhooks.user_id IN (
+        SELECT feed_follows.user_id FROM feed_follows
+        WHERE feed_follows.feed_id = $1
+    )
+);
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, created_at, webhook_id, post_id, attempt, status_code, error, succeeded)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+);
+
+-- name: GetWebhookDeliveriesForUser :many
+SELECT
+    webhook_deliveries.*,
+    webhooks.url AS webhook_url
+FROM webhook_deliveries
+INNER JOIN webhooks
+ON webhook_deliveries.webhook_id = webhooks.id
+WHERE webhooks.user_id = $1
+ORDER BY webhook_deliveries.created_at DESC
+LIMIT $2;
+
+-- name: CountWebhooks :one
+SELECT COUNT(*) AS total, COUNT(webhooks.feed_id) AS for_feeds FROM webhooks;
\ No newline at end of file
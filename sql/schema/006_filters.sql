@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE filters(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    feed_id UUID,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'only')),
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    pattern TEXT NOT NULL,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_feed_id
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE filters;
\ No newline at end of file
@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE webhooks(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    feed_id UUID,
+    url TEXT NOT NULL,
+    keyword TEXT,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_feed_id
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE webhook_deliveries(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    webhook_id UUID NOT NULL,
+    post_id UUID NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    succeeded BOOLEAN NOT NULL,
+    CONSTRAINT fk_webhook_id
+    FOREIGN KEY (webhook_id)
+    REFERENCES webhooks(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
\ No newline at end of file
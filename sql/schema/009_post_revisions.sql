@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE post_revisions(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    title TEXT,
+    url TEXT,
+    description TEXT,
+    published_at TIMESTAMP,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_revisions;
\ No newline at end of file
@@ -0,0 +1,31 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content TEXT;
+
+ALTER TABLE posts
+ADD COLUMN author TEXT;
+
+ALTER TABLE posts
+ADD COLUMN comments_url TEXT;
+
+CREATE TABLE post_categories(
+    post_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+    PRIMARY KEY(post_id, name)
+);
+
+-- +goose Down
+DROP TABLE post_categories;
+
+ALTER TABLE posts
+DROP COLUMN comments_url;
+
+ALTER TABLE posts
+DROP COLUMN author;
+
+ALTER TABLE posts
+DROP COLUMN content;
\ No newline at end of file
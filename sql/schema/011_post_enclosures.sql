@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE post_enclosures(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    source TEXT NOT NULL,
+    media_type TEXT,
+    length BIGINT,
+    duration TEXT,
+    episode TEXT,
+    image_url TEXT,
+    thumbnail_url TEXT,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+    UNIQUE(post_id, url)
+);
+
+-- +goose Down
+DROP TABLE post_enclosures;
\ No newline at end of file
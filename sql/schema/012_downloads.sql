@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE downloads(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    enclosure_id UUID NOT NULL,
+    path TEXT UNIQUE NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    CONSTRAINT fk_enclosure_id
+    FOREIGN KEY (enclosure_id)
+    REFERENCES post_enclosures(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE downloads;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+-- feeds with posts saved before gator kept track of guids, which got their
+-- url as a guid instead; the next fetch of each feed gives them their real
+-- guids, see Scraper.adoptLegacyPosts
+ALTER TABLE feeds
+ADD COLUMN legacy_guids BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE feeds
+SET legacy_guids = TRUE
+WHERE EXISTS (
+    SELECT 1 FROM posts
+    WHERE posts.feed_id = feeds.id AND posts.guid = posts.url
+);
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN legacy_guids;
\ No newline at end of file
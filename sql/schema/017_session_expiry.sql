@@ -0,0 +1,11 @@
+-- +goose Up
+-- sessions from before they could expire are ended, so everyone with a
+-- password logs in again once
+DELETE FROM sessions;
+
+ALTER TABLE sessions
+ADD COLUMN expires_at TIMESTAMP NOT NULL;
+
+-- +goose Down
+ALTER TABLE sessions
+DROP COLUMN expires_at;
\ No newline at end of file
@@ -0,0 +1,9 @@
+package schema
+
+import "embed"
+
+// FS holds the PostgreSQL migrations at its root and the SQLite ones under
+// sqlite/.
+//
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,189 @@
+-- +goose Up
+CREATE TABLE users(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT UNIQUE NOT NULL,
+    password_hash TEXT
+);
+
+CREATE TABLE feeds(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT UNIQUE NOT NULL,
+    user_id TEXT NOT NULL,
+    last_fetched_at TIMESTAMP,
+    title TEXT,
+    site_url TEXT,
+    description TEXT,
+    image_url TEXT,
+    language TEXT,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE feed_follows(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL,
+    feed_id TEXT NOT NULL,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_feed_id
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE,
+    UNIQUE(user_id, feed_id)
+);
+
+CREATE TABLE posts(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT,
+    url TEXT,
+    description TEXT,
+    published_at TIMESTAMP,
+    feed_id TEXT NOT NULL,
+    guid TEXT NOT NULL,
+    content TEXT,
+    author TEXT,
+    comments_url TEXT,
+    CONSTRAINT fk_feed_id
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE,
+    UNIQUE(feed_id, guid)
+);
+
+CREATE TABLE filters(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL,
+    feed_id TEXT,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'only')),
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    pattern TEXT NOT NULL,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_feed_id
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE webhooks(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL,
+    feed_id TEXT,
+    url TEXT NOT NULL,
+    keyword TEXT,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_feed_id
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE webhook_deliveries(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    webhook_id TEXT NOT NULL,
+    post_id TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    succeeded BOOLEAN NOT NULL,
+    CONSTRAINT fk_webhook_id
+    FOREIGN KEY (webhook_id)
+    REFERENCES webhooks(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE post_revisions(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id TEXT NOT NULL,
+    title TEXT,
+    url TEXT,
+    description TEXT,
+    published_at TIMESTAMP,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE post_categories(
+    post_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+    PRIMARY KEY(post_id, name)
+);
+
+CREATE TABLE post_enclosures(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    source TEXT NOT NULL,
+    media_type TEXT,
+    length BIGINT,
+    duration TEXT,
+    episode TEXT,
+    image_url TEXT,
+    thumbnail_url TEXT,
+    CONSTRAINT fk_post_id
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+    UNIQUE(post_id, url)
+);
+
+CREATE TABLE downloads(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    enclosure_id TEXT NOT NULL,
+    path TEXT UNIQUE NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    CONSTRAINT fk_enclosure_id
+    FOREIGN KEY (enclosure_id)
+    REFERENCES post_enclosures(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE downloads;
+DROP TABLE post_enclosures;
+DROP TABLE post_categories;
+DROP TABLE post_revisions;
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
+DROP TABLE filters;
+DROP TABLE posts;
+DROP TABLE feed_follows;
+DROP TABLE feeds;
+DROP TABLE users;
\ No newline at end of file
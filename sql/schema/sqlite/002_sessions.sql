@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE sessions(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE sessions;
\ No newline at end of file
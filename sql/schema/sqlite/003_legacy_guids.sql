@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQLite databases have always kept track of guids, so no feed needs its
+-- posts' guids fixing, but the column keeps the schema in line with
+-- PostgreSQL's
+ALTER TABLE feeds
+ADD COLUMN legacy_guids BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN legacy_guids;
\ No newline at end of file
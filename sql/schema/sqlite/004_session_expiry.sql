@@ -0,0 +1,31 @@
+-- +goose Up
+-- SQLite can only add a NOT NULL column with a default, so the table is
+-- made again; sessions from before they could expire are ended, so everyone
+-- with a password logs in again once
+DROP TABLE sessions;
+
+CREATE TABLE sessions(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE sessions;
+
+CREATE TABLE sessions(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    CONSTRAINT fk_user_id
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
\ No newline at end of file